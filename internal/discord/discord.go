@@ -14,6 +14,7 @@ import (
 type DiscordWebhook struct {
 	webhookURL string
 	client     *http.Client
+	bucket     *rateLimitBucket
 }
 
 // WebhookMessage represents a Discord webhook message payload
@@ -23,10 +24,11 @@ type WebhookMessage struct {
 }
 
 type Embed struct {
-	Title  string       `json:"title,omitempty"`
-	Fields []EmbedField `json:"fields,omitempty"`
-	Image  EmbedImage   `json:"image,omitempty"`
-	URL    string       `json:"url,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Image       EmbedImage   `json:"image,omitempty"`
+	URL         string       `json:"url,omitempty"`
 }
 
 type EmbedField struct {
@@ -46,6 +48,7 @@ func NewDiscordWebhook(webhookURL string) *DiscordWebhook {
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		bucket: bucketFor(webhookURL),
 	}
 }
 
@@ -54,9 +57,12 @@ func NewDiscordWebhookWithClient(webhookURL string, client *http.Client) *Discor
 	return &DiscordWebhook{
 		webhookURL: webhookURL,
 		client:     client,
+		bucket:     bucketFor(webhookURL),
 	}
 }
 
+// PostMessage sends the message, pacing requests to stay within the webhook's rate limit and
+// transparently retrying when Discord responds with 429 Too Many Requests
 func (d DiscordWebhook) PostMessage(ctx context.Context, message WebhookMessage) error {
 	if message.Content == "" {
 		return fmt.Errorf("message content cannot be empty")
@@ -68,10 +74,59 @@ func (d DiscordWebhook) PostMessage(ctx context.Context, message WebhookMessage)
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewBuffer(body))
+	if err := d.bucket.acquire(ctx); err != nil {
+		return fmt.Errorf("failed waiting for rate limit queue: %w", err)
+	}
+	defer d.bucket.release()
+
+	for attempt := 0; ; attempt++ {
+		if err := d.bucket.wait(ctx); err != nil {
+			return fmt.Errorf("failed waiting for rate limit reset: %w", err)
+		}
+
+		resp, err := d.send(ctx, body)
+		if err != nil {
+			return err
+		}
+
+		respBody, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		d.bucket.update(resp.Header)
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			slog.Info("message sent successfully to Discord", "status_code", resp.StatusCode)
+			return nil
+		}
+
+		if readErr != nil {
+			slog.Error("failed to send message to Discord and failed to read error response",
+				"status_code", resp.StatusCode, "read_error", readErr)
+			return fmt.Errorf("failed to send message (status: %d) and failed to read error response: %w",
+				resp.StatusCode, readErr)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			delay := retryAfter(resp.Header, respBody)
+			slog.Warn("rate limited by Discord, retrying", "retry_after", delay, "attempt", attempt+1)
+			if err := sleep(ctx, delay); err != nil {
+				return fmt.Errorf("failed waiting to retry rate limited message: %w", err)
+			}
+			continue
+		}
+
+		slog.Error("failed to send message to Discord",
+			"status_code", resp.StatusCode,
+			"response_body", string(respBody))
+
+		return fmt.Errorf("failed to send message (status: %d): %s", resp.StatusCode, string(respBody))
+	}
+}
+
+func (d DiscordWebhook) send(ctx context.Context, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewReader(body))
 	if err != nil {
 		slog.Error("failed to create HTTP request", "error", err)
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -79,27 +134,8 @@ func (d DiscordWebhook) PostMessage(ctx context.Context, message WebhookMessage)
 	resp, err := d.client.Do(req)
 	if err != nil {
 		slog.Error("error posting to discord", "error", err)
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		slog.Info("message sent successfully to Discord", "status_code", resp.StatusCode)
-		return nil
-	}
-
-	// Read response body for error details
-	respBody, readErr := io.ReadAll(resp.Body)
-	if readErr != nil {
-		slog.Error("failed to send message to Discord and failed to read error response",
-			"status_code", resp.StatusCode, "read_error", readErr)
-		return fmt.Errorf("failed to send message (status: %d) and failed to read error response: %w",
-			resp.StatusCode, readErr)
-	}
-
-	slog.Error("failed to send message to Discord",
-		"status_code", resp.StatusCode,
-		"response_body", string(respBody))
 
-	return fmt.Errorf("failed to send message (status: %d): %s", resp.StatusCode, string(respBody))
+	return resp, nil
 }
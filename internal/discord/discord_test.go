@@ -0,0 +1,162 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBucketServer simulates a Discord webhook bucket allowing limit requests per window,
+// reporting its state in X-RateLimit headers and rejecting excess requests with 429
+type fakeBucketServer struct {
+	limit  int
+	window time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	used        int
+	inFlight    int
+
+	accepted    atomic.Int32
+	rejected    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (f *fakeBucketServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.inFlight++
+	if int32(f.inFlight) > f.maxInFlight.Load() {
+		f.maxInFlight.Store(int32(f.inFlight))
+	}
+
+	now := time.Now()
+	if now.Sub(f.windowStart) >= f.window {
+		f.windowStart = now
+		f.used = 0
+	}
+	resetAfter := f.window - now.Sub(f.windowStart)
+
+	limited := f.used >= f.limit
+	if !limited {
+		f.used++
+	}
+	remaining := f.limit - f.used
+	f.mu.Unlock()
+
+	// Simulate latency so overlapping requests would be observed
+	time.Sleep(5 * time.Millisecond)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+
+	w.Header().Set("X-RateLimit-Limit", fmt.Sprint(f.limit))
+	w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(remaining))
+	w.Header().Set("X-RateLimit-Reset-After", fmt.Sprintf("%.3f", resetAfter.Seconds()))
+
+	if limited {
+		f.rejected.Add(1)
+		w.Header().Set("Retry-After", fmt.Sprintf("%.3f", resetAfter.Seconds()))
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, `{"message": "You are being rate limited.", "retry_after": %.3f, "global": false}`, resetAfter.Seconds())
+		return
+	}
+
+	f.accepted.Add(1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func Test_PostMessage_PacesRequestsWithinBucket(t *testing.T) {
+	bucket := &fakeBucketServer{limit: 2, window: 100 * time.Millisecond}
+	server := httptest.NewServer(bucket)
+	defer server.Close()
+
+	webhook := NewDiscordWebhook(server.URL)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, webhook.PostMessage(context.Background(), WebhookMessage{Content: "hello"}))
+	}
+
+	assert.Equal(t, int32(5), bucket.accepted.Load())
+	assert.Zero(t, bucket.rejected.Load(), "requests should be paced rather than rejected")
+}
+
+func Test_PostMessage_QueuesRequestsPerWebhook(t *testing.T) {
+	bucket := &fakeBucketServer{limit: 3, window: 100 * time.Millisecond}
+	server := httptest.NewServer(bucket)
+	defer server.Close()
+
+	// Separate clients for the same webhook share its bucket
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, NewDiscordWebhook(server.URL).PostMessage(context.Background(), WebhookMessage{Content: "hello"}))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(8), bucket.accepted.Load())
+	assert.Equal(t, int32(1), bucket.maxInFlight.Load())
+	assert.Zero(t, bucket.rejected.Load())
+}
+
+func Test_PostMessage_RetriesAfterTooManyRequests(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Shared or global limits aren't reflected in the bucket headers
+			w.Header().Set("X-RateLimit-Global", "true")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.05, "global": true}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	require.NoError(t, NewDiscordWebhook(server.URL).PostMessage(context.Background(), WebhookMessage{Content: "hello"}))
+
+	assert.Equal(t, int32(2), requests.Load())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "retry_after from the body should be honoured")
+}
+
+func Test_PostMessage_GivesUpAfterRepeatedRateLimits(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "0.001")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	err := NewDiscordWebhook(server.URL).PostMessage(context.Background(), WebhookMessage{Content: "hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "429")
+	assert.Equal(t, int32(maxRateLimitRetries+1), requests.Load())
+}
+
+func Test_PostMessage_DoesNotWaitPastDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := NewDiscordWebhook(server.URL).PostMessage(ctx, WebhookMessage{Content: "hello"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
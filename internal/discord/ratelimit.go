@@ -0,0 +1,135 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRateLimitRetries bounds how many 429 responses a single message will wait out
+	maxRateLimitRetries = 5
+	// defaultRetryAfter is used when Discord responds 429 without saying how long to wait
+	defaultRetryAfter = 1 * time.Second
+)
+
+var (
+	bucketsMu sync.Mutex
+	buckets   = make(map[string]*rateLimitBucket)
+)
+
+// rateLimitBucket tracks Discord's rate limit for a single webhook. Requests to the webhook
+// are queued so only one is in flight at a time, and paced so the bucket is never exhausted.
+type rateLimitBucket struct {
+	// queue holds a token while a request is in flight
+	queue     chan struct{}
+	remaining int
+	resetAt   time.Time
+}
+
+// bucketFor returns the bucket shared by every client of the webhook
+func bucketFor(webhookURL string) *rateLimitBucket {
+	bucketsMu.Lock()
+	defer bucketsMu.Unlock()
+
+	bucket, ok := buckets[webhookURL]
+	if !ok {
+		bucket = &rateLimitBucket{queue: make(chan struct{}, 1), remaining: -1}
+		buckets[webhookURL] = bucket
+	}
+	return bucket
+}
+
+// acquire waits for the webhook's turn. The caller must call release when done.
+func (b *rateLimitBucket) acquire(ctx context.Context) error {
+	select {
+	case b.queue <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *rateLimitBucket) release() {
+	<-b.queue
+}
+
+// wait blocks until the bucket has capacity. It must be called while holding the queue.
+func (b *rateLimitBucket) wait(ctx context.Context) error {
+	if b.remaining != 0 {
+		return nil
+	}
+	return sleep(ctx, time.Until(b.resetAt))
+}
+
+// update records the rate limit state reported by a response. It must be called while
+// holding the queue.
+func (b *rateLimitBucket) update(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	resetAfter, ok := parseSeconds(header.Get("X-RateLimit-Reset-After"))
+	if !ok {
+		return
+	}
+
+	b.remaining = remaining
+	b.resetAt = time.Now().Add(resetAfter)
+}
+
+// retryAfter returns how long Discord asked us to wait before retrying a 429 response,
+// preferring the Retry-After header and falling back to the retry_after body field
+func retryAfter(header http.Header, body []byte) time.Duration {
+	if delay, ok := parseSeconds(header.Get("Retry-After")); ok {
+		return delay
+	}
+
+	var payload struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.RetryAfter > 0 {
+		return time.Duration(payload.RetryAfter * float64(time.Second))
+	}
+
+	return defaultRetryAfter
+}
+
+// parseSeconds parses a (possibly fractional) number of seconds as used by Discord's headers
+func parseSeconds(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// sleep waits for the given duration, returning early with an error if ctx is cancelled or
+// its deadline would pass first
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return context.DeadlineExceeded
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
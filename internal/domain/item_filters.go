@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ItemFilters narrow down the items returned by Vinted, whose text search is fuzzy, before
+// they are treated as new. Words are matched case-insensitively as whole words against the
+// item's title and brand; patterns are Go regular expressions.
+type ItemFilters struct {
+	// IncludeWords must all appear in the title or brand
+	IncludeWords []string `json:"include_words,omitempty"`
+	// ExcludeWords must not appear in the title or brand
+	ExcludeWords []string `json:"exclude_words,omitempty"`
+	// TitlePattern, when set, must match the title
+	TitlePattern string `json:"title_pattern,omitempty"`
+	// BrandPattern, when set, must match the brand
+	BrandPattern string `json:"brand_pattern,omitempty"`
+	// Sellers restricts which sellers' items are kept
+	Sellers SellerRules `json:"sellers"`
+	// MaxTotalPrice, when set, drops items whose price including buyer protection and
+	// other fees exceeds it
+	MaxTotalPrice float64 `json:"max_total_price,omitempty"`
+	// MaxDealPercentile, when set, only keeps items priced below this percentile of comparable
+	// listings with the same brand and size. Items without enough comparables are kept.
+	MaxDealPercentile float64 `json:"max_deal_percentile,omitempty"`
+}
+
+// ItemMatcher is a compiled set of ItemFilters
+type ItemMatcher struct {
+	include      []*regexp.Regexp
+	exclude      []*regexp.Regexp
+	titlePattern *regexp.Regexp
+	brandPattern *regexp.Regexp
+}
+
+// Validate reports whether the filters can be compiled
+func (f ItemFilters) Validate() error {
+	if _, err := f.Matcher(); err != nil {
+		return err
+	}
+	if f.MaxTotalPrice < 0 {
+		return fmt.Errorf("max total price cannot be negative")
+	}
+	if f.MaxDealPercentile < 0 || f.MaxDealPercentile > 100 {
+		return fmt.Errorf("max deal percentile must be between 0 and 100")
+	}
+	return f.Sellers.Validate()
+}
+
+// Matcher compiles the filters for matching against items
+func (f ItemFilters) Matcher() (*ItemMatcher, error) {
+	matcher := &ItemMatcher{}
+
+	for _, word := range f.IncludeWords {
+		re, err := compileWord(word)
+		if err != nil {
+			return nil, err
+		}
+		matcher.include = append(matcher.include, re)
+	}
+
+	for _, word := range f.ExcludeWords {
+		re, err := compileWord(word)
+		if err != nil {
+			return nil, err
+		}
+		matcher.exclude = append(matcher.exclude, re)
+	}
+
+	var err error
+	if matcher.titlePattern, err = compilePattern("title", f.TitlePattern); err != nil {
+		return nil, err
+	}
+	if matcher.brandPattern, err = compilePattern("brand", f.BrandPattern); err != nil {
+		return nil, err
+	}
+
+	return matcher, nil
+}
+
+// Matches reports whether an item with the given title and brand passes the filters
+func (m *ItemMatcher) Matches(title, brand string) bool {
+	text := title + " " + brand
+
+	for _, re := range m.include {
+		if !re.MatchString(text) {
+			return false
+		}
+	}
+
+	for _, re := range m.exclude {
+		if re.MatchString(text) {
+			return false
+		}
+	}
+
+	if m.titlePattern != nil && !m.titlePattern.MatchString(title) {
+		return false
+	}
+
+	if m.brandPattern != nil && !m.brandPattern.MatchString(brand) {
+		return false
+	}
+
+	return true
+}
+
+func compileWord(word string) (*regexp.Regexp, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, fmt.Errorf("filter words cannot be empty")
+	}
+	// Boundaries are any non-letter so accented words in non-English listings stay whole
+	return regexp.MustCompile(`(?i)(^|[^\p{L}\p{M}\p{N}_])` + regexp.QuoteMeta(word) + `($|[^\p{L}\p{M}\p{N}_])`), nil
+}
+
+func compilePattern(name, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern %q: %w", name, pattern, err)
+	}
+	return re, nil
+}
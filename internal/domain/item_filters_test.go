@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ItemMatcher_Matches(t *testing.T) {
+	filters := ItemFilters{
+		IncludeWords: []string{"wax", "jacket"},
+		ExcludeWords: []string{"style", "kids"},
+		TitlePattern: `(?i)\b(bedale|beaufort)\b`,
+		BrandPattern: `(?i)^barbour$`,
+	}
+	matcher, err := filters.Matcher()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		title string
+		brand string
+		want  bool
+	}{
+		{"matches everything", "Bedale Wax Jacket", "Barbour", true},
+		{"missing required word", "Bedale Jacket", "Barbour", false},
+		{"required word inside another word", "Bedale Waxed Jacket", "Barbour", false},
+		{"excluded word", "Beaufort wax jacket Barbour-style", "Barbour", false},
+		{"excluded word in brand", "Beaufort wax jacket", "Barbour Kids", false},
+		{"title pattern mismatch", "Ashby wax jacket", "Barbour", false},
+		{"brand pattern mismatch", "Bedale wax jacket", "Barbour International", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matcher.Matches(tt.title, tt.brand))
+		})
+	}
+}
+
+func Test_ItemFilters_EmptyMatchesEverything(t *testing.T) {
+	matcher, err := ItemFilters{}.Matcher()
+	require.NoError(t, err)
+	assert.True(t, matcher.Matches("anything", ""))
+}
+
+func Test_ItemFilters_Validate(t *testing.T) {
+	assert.Error(t, ItemFilters{TitlePattern: "(unclosed"}.Validate())
+	assert.Error(t, ItemFilters{BrandPattern: "[a-"}.Validate())
+	assert.Error(t, ItemFilters{IncludeWords: []string{" "}}.Validate())
+	assert.NoError(t, ItemFilters{IncludeWords: []string{"c++"}, TitlePattern: "^a"}.Validate())
+}
+
+func Test_ItemMatcher_MatchesAccentedWholeWords(t *testing.T) {
+	matcher, err := ItemFilters{IncludeWords: []string{"caf"}, ExcludeWords: []string{"tâché"}}.Matcher()
+	require.NoError(t, err)
+
+	assert.False(t, matcher.Matches("Tasse café vintage", ""))
+	assert.True(t, matcher.Matches("Mug caf édition", ""))
+	assert.False(t, matcher.Matches("Mug caf TÂCHÉ", ""))
+	assert.True(t, matcher.Matches("Mug caf détâché", ""))
+}
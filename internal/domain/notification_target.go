@@ -0,0 +1,19 @@
+package domain
+
+const (
+	NotificationTargetDiscord  = "discord"
+	NotificationTargetSlack    = "slack"
+	NotificationTargetTelegram = "telegram"
+	NotificationTargetEmail    = "email"
+	NotificationTargetWebhook  = "webhook"
+	NotificationTargetNtfy     = "ntfy"
+	NotificationTargetGotify   = "gotify"
+)
+
+// NotificationTarget is a destination that new items for a search are sent to, such as a
+// Discord channel webhook. Options carries any channel-specific settings.
+type NotificationTarget struct {
+	Type    string            `json:"type"`
+	URL     string            `json:"url,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
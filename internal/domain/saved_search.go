@@ -1,22 +1,58 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultInterval = 1 * time.Hour
+	MinimumInterval = 1 * time.Minute
+)
 
 type SavedSearch struct {
 	ID           int
 	Name         string
 	OriginalURL  string
 	SearchParams *SearchParams
-	LastChecked  time.Time
-	Active       bool
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	Interval     time.Duration
+	// NotificationTargets overrides where new items are sent. When empty, the
+	// globally configured targets are used.
+	NotificationTargets []NotificationTarget
+	// Filters are applied to fetched items before they are treated as new
+	Filters ItemFilters
+	// PriceDropAlerts configures notifications when already-seen items are reduced
+	PriceDropAlerts PriceDropAlerts
+	LastChecked     time.Time
+	Active          bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// PriceDropAlerts configures notifications for price reductions on items that were already seen.
+// Prices are only compared for items on the pages fetched by a scrape, and paging stops at the
+// first item already observed, so reductions are caught for listings still near the top of the
+// search results rather than for every tracked item.
+type PriceDropAlerts struct {
+	Enabled bool `json:"enabled"`
+	// MinDropPercent is the smallest reduction, as a percentage of the last observed price,
+	// worth notifying about
+	MinDropPercent float64 `json:"min_drop_percent,omitempty"`
+}
+
+// Validate reports whether the settings are usable
+func (p PriceDropAlerts) Validate() error {
+	if p.MinDropPercent < 0 || p.MinDropPercent >= 100 {
+		return fmt.Errorf("minimum price drop must be between 0 and 100 percent")
+	}
+	return nil
 }
 
 func NewSavedSearch(searchParams *SearchParams) *SavedSearch {
 	return &SavedSearch{
 		Name:         searchParams.SearchText,
 		SearchParams: searchParams,
+		Interval:     DefaultInterval,
 		Active:       true,
 	}
 }
@@ -24,3 +60,23 @@ func NewSavedSearch(searchParams *SearchParams) *SavedSearch {
 func (s *SavedSearch) IsActive() bool {
 	return s.Active
 }
+
+// IsDue reports whether the search is active and its interval has elapsed since it was last checked
+func (s *SavedSearch) IsDue(now time.Time) bool {
+	if !s.IsActive() {
+		return false
+	}
+	return !now.Before(s.LastChecked.Add(s.Interval))
+}
+
+// ParseInterval parses a duration string such as "2m" or "6h" and enforces the minimum interval
+func ParseInterval(interval string) (time.Duration, error) {
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q: %w", interval, err)
+	}
+	if d < MinimumInterval {
+		return 0, fmt.Errorf("interval must be at least %s", MinimumInterval)
+	}
+	return d, nil
+}
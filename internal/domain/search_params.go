@@ -3,6 +3,7 @@ package domain
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -10,21 +11,79 @@ type SearchParams struct {
 	// Required parameters
 	SearchText string
 
+	// Domain is the host of the Vinted site to search, e.g. www.vinted.fr. Searches saved
+	// before other countries were supported leave it empty and search DefaultVintedDomain.
+	Domain string
+
 	// Optional parameters
-	CatalogIDs  []int
-	Page        int
-	SizeIDs     []int
-	BrandIDs    []int
-	StatusIDs   []int
-	PatternsIDs []int
-	PriceFrom   float64
-	PriceTo     float64
-	Currency    string
+	CatalogIDs           []int
+	Page                 int
+	SizeIDs              []int
+	BrandIDs             []int
+	StatusIDs            []int
+	PatternsIDs          []int
+	ColorIDs             []int
+	MaterialIDs          []int
+	VideoGamePlatformIDs []int
+	VideoGameRatingIDs   []int
+	DisposalIDs          []int
+	PriceFrom            float64
+	PriceTo              float64
+	Currency             string
+	// Order is the sort order of the search on the Vinted website. Empty means newest_first,
+	// which the API is always queried with so new items can be detected.
+	Order string
+}
+
+const DefaultOrder = "newest_first"
+
+// idParams lists the query parameters of the ID filters shared by the website and the API,
+// other than catalogs which the two name differently
+func (s *SearchParams) idParams() []struct {
+	key string
+	ids []int
+} {
+	return []struct {
+		key string
+		ids []int
+	}{
+		{"size_ids[]", s.SizeIDs},
+		{"brand_ids[]", s.BrandIDs},
+		{"status_ids[]", s.StatusIDs},
+		{"patterns_ids[]", s.PatternsIDs},
+		{"color_ids[]", s.ColorIDs},
+		{"material_ids[]", s.MaterialIDs},
+		{"video_game_platform_ids[]", s.VideoGamePlatformIDs},
+		{"video_game_rating_ids[]", s.VideoGameRatingIDs},
+		{"disposal[]", s.DisposalIDs},
+	}
+}
+
+// VintedDomain returns the host of the Vinted site the search runs against
+func (s *SearchParams) VintedDomain() string {
+	if s.Domain == "" {
+		return DefaultVintedDomain
+	}
+	return s.Domain
+}
+
+// BaseURL returns the root URL of the Vinted site the search runs against
+func (s *SearchParams) BaseURL() string {
+	return "https://" + s.VintedDomain()
+}
+
+// CurrencyCode returns the currency prices are given in, which is the site's own currency
+// unless the search asks for another
+func (s *SearchParams) CurrencyCode() string {
+	if s.Currency != "" {
+		return s.Currency
+	}
+	return VintedDomainCurrency(s.VintedDomain())
 }
 
 // https://www.vinted.co.uk/api/v2/catalog/items?page=1&per_page=96&time=1754854403&gen_session_id=true&search_text=universal+works+men&catalog_ids=2051&price_from=0&price_to=100&currency=GBP&order=newest_first&size_ids=209&brand_ids=378695&status_ids=6&patterns_ids=28
 func (s *SearchParams) ToApiURL() (string, error) {
-	baseURL := "https://www.vinted.co.uk/api/v2/catalog/items"
+	baseURL := s.BaseURL() + "/api/v2/catalog/items"
 	values := url.Values{}
 
 	if s.SearchText == "" {
@@ -33,12 +92,8 @@ func (s *SearchParams) ToApiURL() (string, error) {
 
 	values.Set("search_text", strings.ReplaceAll(s.SearchText, " ", "+"))
 
-	if s.Currency != "" {
-		values.Set("currency", s.Currency)
-	}
-
-	if s.Currency != "" {
-		values.Set("currency", s.Currency)
+	if currency := s.CurrencyCode(); currency != "" {
+		values.Set("currency", currency)
 	}
 
 	if s.Page != 0 {
@@ -53,33 +108,12 @@ func (s *SearchParams) ToApiURL() (string, error) {
 		values.Set("price_to", fmt.Sprintf("%.2f", s.PriceTo))
 	}
 
-	if len(s.CatalogIDs) > 0 {
-		for _, id := range s.CatalogIDs {
-			values.Add("catalog_ids[]", fmt.Sprintf("%d", id))
-		}
-	}
-	if len(s.SizeIDs) > 0 {
-		for _, id := range s.SizeIDs {
-			values.Add("size_ids[]", fmt.Sprintf("%d", id))
-		}
-	}
-	if len(s.BrandIDs) > 0 {
-		for _, id := range s.BrandIDs {
-			values.Add("brand_ids[]", fmt.Sprintf("%d", id))
-		}
-	}
-	if len(s.StatusIDs) > 0 {
-		for _, id := range s.StatusIDs {
-			values.Add("status_ids[]", fmt.Sprintf("%d", id))
-		}
-	}
-	if len(s.PatternsIDs) > 0 {
-		for _, id := range s.PatternsIDs {
-			values.Add("patterns_ids[]", fmt.Sprintf("%d", id))
-		}
+	addIDs(values, "catalog_ids[]", s.CatalogIDs)
+	for _, param := range s.idParams() {
+		addIDs(values, param.key, param.ids)
 	}
 
-	values.Add("order", "newest_first")
+	values.Add("order", DefaultOrder)
 
 	encoded := fmt.Sprintf("%s?%s", baseURL, values.Encode())
 	// hacky - but the vinted API returns different results if you use the encoded form (often less)
@@ -87,4 +121,51 @@ func (s *SearchParams) ToApiURL() (string, error) {
 	return encoded, nil
 }
 
-// TODO: Add ToWebURL method
+// ToWebURL returns the URL of the search on the Vinted website. It is the inverse of
+// vinted.ParseVintedURL, so the search can be linked back to exactly as it was saved.
+//
+// https://www.vinted.co.uk/catalog?search_text=universal+works+men&catalog[]=2051&brand_ids[]=378695&size_ids[]=209&status_ids[]=6&patterns_ids[]=28&price_from=1&price_to=100&currency=GBP&order=newest_first
+func (s *SearchParams) ToWebURL() (string, error) {
+	if s.SearchText == "" {
+		return "", fmt.Errorf("missing required parameter: search_text")
+	}
+
+	values := url.Values{}
+	values.Set("search_text", s.SearchText)
+
+	if s.Currency != "" {
+		values.Set("currency", s.Currency)
+	}
+
+	if s.Page != 0 {
+		values.Set("page", strconv.Itoa(s.Page))
+	}
+
+	// Prices are written in full so they parse back to the same value
+	if s.PriceFrom != 0 {
+		values.Set("price_from", strconv.FormatFloat(s.PriceFrom, 'f', -1, 64))
+	}
+
+	if s.PriceTo != 0 {
+		values.Set("price_to", strconv.FormatFloat(s.PriceTo, 'f', -1, 64))
+	}
+
+	addIDs(values, "catalog[]", s.CatalogIDs)
+	for _, param := range s.idParams() {
+		addIDs(values, param.key, param.ids)
+	}
+
+	order := s.Order
+	if order == "" {
+		order = DefaultOrder
+	}
+	values.Set("order", order)
+
+	return fmt.Sprintf("%s/catalog?%s", s.BaseURL(), values.Encode()), nil
+}
+
+func addIDs(values url.Values, key string, ids []int) {
+	for _, id := range ids {
+		values.Add(key, strconv.Itoa(id))
+	}
+}
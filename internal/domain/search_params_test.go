@@ -32,12 +32,33 @@ func Test_ToApiURL_WithCompleteSearchTerms(t *testing.T) {
 	assert.Equal(t, expectedURL, escapedActualURL, "generated API URL should match expected URL")
 }
 
+func Test_ToApiURL_IncludesAllFilters(t *testing.T) {
+	params := &SearchParams{
+		SearchText:           "zelda",
+		ColorIDs:             []int{1, 12},
+		MaterialIDs:          []int{44},
+		VideoGamePlatformIDs: []int{1281},
+		VideoGameRatingIDs:   []int{3},
+		DisposalIDs:          []int{1},
+		Order:                "price_low_to_high",
+	}
+
+	// The API is always sorted by newest so new items can be detected
+	expectedURL := "https://www.vinted.co.uk/api/v2/catalog/items?color_ids[]=1&color_ids[]=12&currency=GBP&disposal[]=1&material_ids[]=44&order=newest_first&search_text=zelda&video_game_platform_ids[]=1281&video_game_rating_ids[]=3"
+	actualURL, err := params.ToApiURL()
+	require.NoError(t, err)
+
+	escapedActualURL, err := url.QueryUnescape(actualURL)
+	require.NoError(t, err)
+	assert.Equal(t, expectedURL, escapedActualURL)
+}
+
 func Test_ToApiURL_WithMinimalSearchTerms(t *testing.T) {
 	params := &SearchParams{
 		SearchText: "universal works",
 	}
 
-	expectedURL := "https://www.vinted.co.uk/api/v2/catalog/items?order=newest_first&search_text=universal works"
+	expectedURL := "https://www.vinted.co.uk/api/v2/catalog/items?currency=GBP&order=newest_first&search_text=universal works"
 	actualURL, err := params.ToApiURL()
 	escapedActualURL, err := url.QueryUnescape(actualURL)
 
@@ -45,3 +66,61 @@ func Test_ToApiURL_WithMinimalSearchTerms(t *testing.T) {
 
 	assert.Equal(t, expectedURL, escapedActualURL, "generated API URL should match expected URL")
 }
+
+func Test_ToApiURL_UsesSearchDomainAndCurrency(t *testing.T) {
+	params := &SearchParams{
+		SearchText: "barbour",
+		Domain:     "www.vinted.fr",
+	}
+
+	expectedURL := "https://www.vinted.fr/api/v2/catalog/items?currency=EUR&order=newest_first&search_text=barbour"
+	actualURL, err := params.ToApiURL()
+	require.NoError(t, err)
+
+	escapedActualURL, err := url.QueryUnescape(actualURL)
+	require.NoError(t, err)
+	assert.Equal(t, expectedURL, escapedActualURL)
+}
+
+func Test_NormaliseVintedDomain(t *testing.T) {
+	vintedDomain, err := NormaliseVintedDomain("vinted.de")
+	require.NoError(t, err)
+	assert.Equal(t, "www.vinted.de", vintedDomain)
+	assert.Equal(t, "EUR", VintedDomainCurrency(vintedDomain))
+
+	vintedDomain, err = NormaliseVintedDomain("WWW.Vinted.co.uk")
+	require.NoError(t, err)
+	assert.Equal(t, "www.vinted.co.uk", vintedDomain)
+
+	vintedDomain, err = NormaliseVintedDomain("m.vinted.fr")
+	require.NoError(t, err)
+	assert.Equal(t, "www.vinted.fr", vintedDomain)
+
+	_, err = NormaliseVintedDomain("notvinted.fr")
+	assert.Error(t, err)
+
+	_, err = NormaliseVintedDomain("www.example.com")
+	assert.Error(t, err)
+}
+
+func Test_ToWebURL(t *testing.T) {
+	params := &SearchParams{
+		SearchText: "universal works men",
+		Domain:     "www.vinted.fr",
+		CatalogIDs: []int{2051},
+		BrandIDs:   []int{378695, 378696},
+		PriceTo:    99.5,
+		Currency:   "EUR",
+	}
+
+	expectedURL := "https://www.vinted.fr/catalog?brand_ids[]=378695&brand_ids[]=378696&catalog[]=2051&currency=EUR&order=newest_first&price_to=99.5&search_text=universal works men"
+	actualURL, err := params.ToWebURL()
+	require.NoError(t, err)
+
+	escapedActualURL, err := url.QueryUnescape(actualURL)
+	require.NoError(t, err)
+	assert.Equal(t, expectedURL, escapedActualURL)
+
+	_, err = (&SearchParams{}).ToWebURL()
+	assert.Error(t, err)
+}
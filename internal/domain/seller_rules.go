@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SellerRules decide which sellers' items are worth notifying about. Sellers are identified
+// by their numeric Vinted user ID or, case-insensitively, by their login.
+type SellerRules struct {
+	// ExcludeBusiness drops items listed by business (pro) sellers
+	ExcludeBusiness bool `json:"exclude_business,omitempty"`
+	// Blocked sellers' items are always dropped, e.g. known resellers
+	Blocked []string `json:"blocked,omitempty"`
+	// Allowed, when set, restricts items to these sellers only
+	Allowed []string `json:"allowed,omitempty"`
+}
+
+// Allows reports whether items from the given seller pass the rules
+func (r SellerRules) Allows(id int, login string, business bool) bool {
+	if r.ExcludeBusiness && business {
+		return false
+	}
+
+	if containsSeller(r.Blocked, id, login) {
+		return false
+	}
+
+	if len(r.Allowed) > 0 && !containsSeller(r.Allowed, id, login) {
+		return false
+	}
+
+	return true
+}
+
+// Validate reports whether every seller reference is usable
+func (r SellerRules) Validate() error {
+	for _, seller := range append(append([]string{}, r.Blocked...), r.Allowed...) {
+		if strings.TrimSpace(seller) == "" {
+			return fmt.Errorf("seller IDs and logins cannot be empty")
+		}
+	}
+	return nil
+}
+
+func containsSeller(sellers []string, id int, login string) bool {
+	for _, seller := range sellers {
+		if MatchesSeller(seller, id, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesSeller reports whether the seller reference names the seller with the given ID or login
+func MatchesSeller(seller string, id int, login string) bool {
+	seller = strings.TrimSpace(seller)
+	if sellerID, err := strconv.Atoi(seller); err == nil && sellerID == id {
+		return true
+	}
+	return login != "" && strings.EqualFold(seller, login)
+}
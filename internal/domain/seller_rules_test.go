@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SellerRules_Allows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    SellerRules
+		id       int
+		login    string
+		business bool
+		want     bool
+	}{
+		{"no rules", SellerRules{}, 1, "anna", true, true},
+		{"business excluded", SellerRules{ExcludeBusiness: true}, 1, "shop", true, false},
+		{"private seller with business excluded", SellerRules{ExcludeBusiness: true}, 1, "anna", false, true},
+		{"blocked by ID", SellerRules{Blocked: []string{"42"}}, 42, "flipper", false, false},
+		{"blocked by login ignoring case", SellerRules{Blocked: []string{"Flipper"}}, 42, "flipper", false, false},
+		{"not blocked", SellerRules{Blocked: []string{"flipper"}}, 7, "anna", false, true},
+		{"allowlisted", SellerRules{Allowed: []string{"anna", "99"}}, 7, "anna", false, true},
+		{"allowlisted by ID", SellerRules{Allowed: []string{"anna", "99"}}, 99, "bob", false, true},
+		{"not allowlisted", SellerRules{Allowed: []string{"anna"}}, 8, "bob", false, false},
+		{"allowlisted but blocked", SellerRules{Allowed: []string{"anna"}, Blocked: []string{"anna"}}, 7, "anna", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rules.Allows(tt.id, tt.login, tt.business))
+		})
+	}
+}
+
+func Test_SellerRules_Validate(t *testing.T) {
+	assert.NoError(t, SellerRules{Blocked: []string{"anna"}, Allowed: []string{"42"}}.Validate())
+	assert.Error(t, SellerRules{Blocked: []string{""}}.Validate())
+	assert.Error(t, ItemFilters{Sellers: SellerRules{Allowed: []string{" "}}}.Validate())
+}
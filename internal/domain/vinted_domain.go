@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultVintedDomain is the site searched by searches saved before other countries were supported
+const DefaultVintedDomain = "www.vinted.co.uk"
+
+// vintedDomainCurrencies maps each supported Vinted site to the currency its listings are priced in
+var vintedDomainCurrencies = map[string]string{
+	"www.vinted.co.uk": "GBP",
+	"www.vinted.ie":    "EUR",
+	"www.vinted.fr":    "EUR",
+	"www.vinted.de":    "EUR",
+	"www.vinted.nl":    "EUR",
+	"www.vinted.be":    "EUR",
+	"www.vinted.lu":    "EUR",
+	"www.vinted.at":    "EUR",
+	"www.vinted.es":    "EUR",
+	"www.vinted.it":    "EUR",
+	"www.vinted.pt":    "EUR",
+	"www.vinted.fi":    "EUR",
+	"www.vinted.lt":    "EUR",
+	"www.vinted.sk":    "EUR",
+	"www.vinted.gr":    "EUR",
+	"www.vinted.hr":    "EUR",
+	"www.vinted.pl":    "PLN",
+	"www.vinted.cz":    "CZK",
+	"www.vinted.hu":    "HUF",
+	"www.vinted.ro":    "RON",
+	"www.vinted.se":    "SEK",
+	"www.vinted.dk":    "DKK",
+}
+
+// NormaliseVintedDomain returns the canonical host of a supported Vinted site, accepting hosts
+// with any subdomain, such as m.vinted.fr, or none
+func NormaliseVintedDomain(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.Index(host, "vinted."); i == 0 || (i > 0 && host[i-1] == '.') {
+		host = "www." + host[i:]
+	}
+
+	if _, ok := vintedDomainCurrencies[host]; !ok {
+		return "", fmt.Errorf("unsupported Vinted domain: %q", host)
+	}
+	return host, nil
+}
+
+// VintedDomainCurrency returns the currency listings on the Vinted site are priced in
+func VintedDomainCurrency(vintedDomain string) string {
+	return vintedDomainCurrencies[vintedDomain]
+}
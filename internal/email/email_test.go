@@ -0,0 +1,41 @@
+package email
+
+import (
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BuildMessage(t *testing.T) {
+	date := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	body := `<p>` + strings.Repeat("Barbour ", 200) + `£40</p>`
+
+	raw, err := buildMessage("watcher@example.com", []string{"a@example.com", "b@example.com"}, "Vinted digest: 3 new items", body, date)
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "watcher@example.com", msg.Header.Get("From"))
+	assert.Equal(t, "a@example.com, b@example.com", msg.Header.Get("To"))
+	assert.Equal(t, "text/html; charset=UTF-8", msg.Header.Get("Content-Type"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Vinted digest: 3 new items", subject)
+
+	decoded, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decoded))
+
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		assert.LessOrEqual(t, len(line), 998, "SMTP lines must not exceed 998 characters")
+	}
+}
@@ -0,0 +1,102 @@
+package gotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type GotifyApp struct {
+	serverURL string
+	appToken  string
+	client    *http.Client
+}
+
+// Message is the payload for Gotify's POST /message endpoint
+type Message struct {
+	Title    string         `json:"title,omitempty"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// NewMarkdownMessage creates a message rendered as markdown by Gotify clients, opening clickURL when tapped
+func NewMarkdownMessage(title, markdown string, priority int, clickURL string) Message {
+	extras := map[string]any{
+		"client::display": map[string]string{"contentType": "text/markdown"},
+	}
+	if clickURL != "" {
+		extras["client::notification"] = map[string]any{
+			"click": map[string]string{"url": clickURL},
+		}
+	}
+
+	return Message{
+		Title:    title,
+		Message:  markdown,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// NewGotifyApp creates a new Gotify client for an application token with sensible defaults
+func NewGotifyApp(serverURL, appToken string) *GotifyApp {
+	return NewGotifyAppWithClient(serverURL, appToken, &http.Client{
+		Timeout: 10 * time.Second,
+	})
+}
+
+// NewGotifyAppWithClient creates a new Gotify client with a custom HTTP client
+func NewGotifyAppWithClient(serverURL, appToken string, client *http.Client) *GotifyApp {
+	return &GotifyApp{
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		appToken:  appToken,
+		client:    client,
+	}
+}
+
+func (g GotifyApp) PostMessage(ctx context.Context, message Message) error {
+	if message.Message == "" {
+		return fmt.Errorf("message cannot be empty")
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		slog.Error("failed to marshal gotify message", "error", err)
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.serverURL+"/message", bytes.NewBuffer(body))
+	if err != nil {
+		slog.Error("failed to create HTTP request", "error", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", g.appToken)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		slog.Error("error posting to gotify", "error", err)
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		slog.Info("message sent successfully to Gotify", "status_code", resp.StatusCode)
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	slog.Error("failed to send message to Gotify",
+		"status_code", resp.StatusCode,
+		"response_body", string(respBody))
+
+	return fmt.Errorf("failed to send message (status: %d): %s", resp.StatusCode, string(respBody))
+}
@@ -0,0 +1,66 @@
+package gotify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PostMessage_SendsTokenAndPriority(t *testing.T) {
+	var gotRequest *http.Request
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequest = r
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	// A trailing slash on the server URL should not produce a double slash
+	app := NewGotifyAppWithClient(server.URL+"/", "AppToken", server.Client())
+	err := app.PostMessage(context.Background(), NewMarkdownMessage("Barbour jacket", "**£45.00**", 8, "https://www.vinted.co.uk/items/1"))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, gotRequest.Method)
+	assert.Equal(t, "/message", gotRequest.URL.Path)
+	assert.Equal(t, "AppToken", gotRequest.Header.Get("X-Gotify-Key"))
+	assert.Equal(t, "application/json", gotRequest.Header.Get("Content-Type"))
+
+	assert.Equal(t, "Barbour jacket", gotBody["title"])
+	assert.Equal(t, "**£45.00**", gotBody["message"])
+	assert.Equal(t, float64(8), gotBody["priority"])
+
+	extras := gotBody["extras"].(map[string]any)
+	assert.Equal(t, "text/markdown", extras["client::display"].(map[string]any)["contentType"])
+	click := extras["client::notification"].(map[string]any)["click"].(map[string]any)
+	assert.Equal(t, "https://www.vinted.co.uk/items/1", click["url"])
+}
+
+func Test_NewMarkdownMessage_OmitsClickWithoutURL(t *testing.T) {
+	message := NewMarkdownMessage("title", "body", 5, "")
+	assert.NotContains(t, message.Extras, "client::notification")
+}
+
+func Test_PostMessage_ReturnsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"Unauthorized","errorCode":401}`))
+	}))
+	defer server.Close()
+
+	app := NewGotifyAppWithClient(server.URL, "wrong", server.Client())
+	err := app.PostMessage(context.Background(), Message{Message: "hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Contains(t, err.Error(), "Unauthorized")
+}
+
+func Test_PostMessage_RequiresMessage(t *testing.T) {
+	app := NewGotifyApp("https://gotify.example", "AppToken")
+	assert.Error(t, app.PostMessage(context.Background(), Message{Title: "empty"}))
+}
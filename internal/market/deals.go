@@ -0,0 +1,31 @@
+package market
+
+import "time"
+
+const (
+	// MinComparables is the number of comparable listings needed for a deal score to be meaningful
+	MinComparables = 5
+	// ComparisonWindow is how far back comparable listings are drawn from
+	ComparisonWindow = 30 * 24 * time.Hour
+)
+
+// ScoreDeal returns the percentile (0-100) of the price among comparable listings' prices, so
+// lower scores are better deals. Listings priced the same count as half above and half below.
+// It reports false when there are too few comparables to judge.
+func ScoreDeal(price float64, comparables []float64) (float64, bool) {
+	if len(comparables) < MinComparables {
+		return 0, false
+	}
+
+	var below float64
+	for _, comparable := range comparables {
+		switch {
+		case comparable < price:
+			below++
+		case comparable == price:
+			below += 0.5
+		}
+	}
+
+	return below / float64(len(comparables)) * 100, true
+}
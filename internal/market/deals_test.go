@@ -0,0 +1,30 @@
+package market
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ScoreDeal(t *testing.T) {
+	comparables := []float64{40, 50, 60, 70, 80, 90, 100, 110, 120, 130}
+
+	score, ok := ScoreDeal(35, comparables)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, score)
+
+	score, ok = ScoreDeal(65, comparables)
+	assert.True(t, ok)
+	assert.Equal(t, 30.0, score)
+
+	score, ok = ScoreDeal(60, comparables)
+	assert.True(t, ok)
+	assert.Equal(t, 25.0, score, "equal prices count half")
+
+	score, ok = ScoreDeal(200, comparables)
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, score)
+
+	_, ok = ScoreDeal(65, comparables[:MinComparables-1])
+	assert.False(t, ok)
+}
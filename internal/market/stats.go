@@ -0,0 +1,138 @@
+package market
+
+import (
+	"math"
+	"sort"
+	"time"
+	"vinted-watcher/internal/storage"
+)
+
+// SearchStats summarises the listings a search has observed
+type SearchStats struct {
+	SearchID int `json:"search_id"`
+	// ListingCount is the number of distinct items the search has observed
+	ListingCount int `json:"listing_count"`
+	// Currency is the currency the price statistics are given in. Listings priced in other
+	// currencies are left out of them.
+	Currency    string   `json:"currency,omitempty"`
+	MedianPrice *float64 `json:"median_price"`
+	P10Price    *float64 `json:"p10_price"`
+	P90Price    *float64 `json:"p90_price"`
+	// SoldCount and RemovedCount are the listings found to have sold or been removed when
+	// re-checked. Only notified items are re-checked, and only when TRACK_SOLD_ITEMS is on, so
+	// they and the averages below stay empty otherwise.
+	SoldCount    int `json:"sold_count"`
+	RemovedCount int `json:"removed_count"`
+	// AverageTimeToDisappearSeconds is how long sold and removed listings were listed for on
+	// average
+	AverageTimeToDisappearSeconds *float64 `json:"average_time_to_disappear_seconds"`
+	// AverageTimeToSellSeconds is how long sold listings were listed for on average
+	AverageTimeToSellSeconds *float64      `json:"average_time_to_sell_seconds"`
+	ListingsPerDay           []DailyVolume `json:"listings_per_day"`
+}
+
+// DailyVolume is the number of listings first observed on a day (UTC)
+type DailyVolume struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// ComputeSearchStats derives market statistics from a search's listings. Each listing's
+// latest observed price is used.
+func ComputeSearchStats(searchID int, listings []storage.SearchListing) SearchStats {
+	stats := SearchStats{
+		SearchID:       searchID,
+		ListingCount:   len(listings),
+		ListingsPerDay: make([]DailyVolume, 0),
+	}
+
+	if stats.ListingCount == 0 {
+		return stats
+	}
+
+	stats.Currency = mostCommonCurrency(listings)
+
+	prices := make([]float64, 0, len(listings))
+	volume := make(map[string]int)
+	var timeToSell, timeToRemove time.Duration
+	for _, l := range listings {
+		if l.Price != nil && l.CurrencyCode == stats.Currency {
+			prices = append(prices, *l.Price)
+		}
+
+		volume[l.FirstObservedAt.UTC().Format(time.DateOnly)]++
+
+		if l.SoldAt != nil {
+			stats.SoldCount++
+			timeToSell += l.SoldAt.Sub(l.FirstSeenAt)
+		} else if l.RemovedAt != nil {
+			stats.RemovedCount++
+			timeToRemove += l.RemovedAt.Sub(l.FirstSeenAt)
+		}
+	}
+
+	if len(prices) > 0 {
+		sort.Float64s(prices)
+		stats.MedianPrice = roundedPrice(Percentile(prices, 50))
+		stats.P10Price = roundedPrice(Percentile(prices, 10))
+		stats.P90Price = roundedPrice(Percentile(prices, 90))
+	}
+
+	if disappeared := stats.SoldCount + stats.RemovedCount; disappeared > 0 {
+		average := (timeToSell + timeToRemove).Seconds() / float64(disappeared)
+		stats.AverageTimeToDisappearSeconds = &average
+	}
+
+	if stats.SoldCount > 0 {
+		average := timeToSell.Seconds() / float64(stats.SoldCount)
+		stats.AverageTimeToSellSeconds = &average
+	}
+
+	for date, count := range volume {
+		stats.ListingsPerDay = append(stats.ListingsPerDay, DailyVolume{Date: date, Count: count})
+	}
+	sort.Slice(stats.ListingsPerDay, func(i, j int) bool {
+		return stats.ListingsPerDay[i].Date < stats.ListingsPerDay[j].Date
+	})
+
+	return stats
+}
+
+// Percentile returns the p-th percentile (0-100) of sorted values, interpolating linearly
+// between the closest ranks
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func mostCommonCurrency(listings []storage.SearchListing) string {
+	counts := make(map[string]int)
+	for _, l := range listings {
+		if l.Price != nil {
+			counts[l.CurrencyCode]++
+		}
+	}
+
+	var currency string
+	for c, count := range counts {
+		if count > counts[currency] || (count == counts[currency] && c < currency) {
+			currency = c
+		}
+	}
+	return currency
+}
+
+func roundedPrice(price float64) *float64 {
+	rounded := math.Round(price*100) / 100
+	return &rounded
+}
@@ -0,0 +1,103 @@
+package market
+
+import (
+	"testing"
+	"time"
+	"vinted-watcher/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listing(itemID int64, price float64, currency string, firstObservedAt time.Time) storage.SearchListing {
+	return storage.SearchListing{ItemID: itemID, Price: &price, CurrencyCode: currency, FirstObservedAt: firstObservedAt, FirstSeenAt: firstObservedAt}
+}
+
+func Test_Percentile(t *testing.T) {
+	values := []float64{10, 20, 30, 40, 50}
+
+	assert.Equal(t, 30.0, Percentile(values, 50))
+	assert.Equal(t, 10.0, Percentile(values, 0))
+	assert.Equal(t, 50.0, Percentile(values, 100))
+	assert.InDelta(t, 14.0, Percentile(values, 10), 0.0001)
+	assert.InDelta(t, 46.0, Percentile(values, 90), 0.0001)
+	assert.Equal(t, 7.0, Percentile([]float64{7}, 90))
+	assert.Zero(t, Percentile(nil, 50))
+}
+
+func Test_ComputeSearchStats(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	soldAfter := func(l storage.SearchListing, d time.Duration) storage.SearchListing {
+		soldAt := l.FirstSeenAt.Add(d)
+		l.SoldAt = &soldAt
+		return l
+	}
+	removed := listing(4, 140, "GBP", day2)
+	removedAt := day2.Add(time.Hour)
+	removed.RemovedAt = &removedAt
+
+	listings := []storage.SearchListing{
+		// Still listed
+		listing(1, 100, "GBP", day1),
+		soldAfter(listing(2, 80, "GBP", day1), 2*time.Hour),
+		soldAfter(listing(3, 60, "GBP", day2), 4*time.Hour),
+		removed,
+		// Priced in another currency so left out of the price statistics
+		listing(5, 1000, "EUR", day2),
+	}
+
+	stats := ComputeSearchStats(9, listings)
+
+	assert.Equal(t, 9, stats.SearchID)
+	assert.Equal(t, 5, stats.ListingCount)
+	assert.Equal(t, "GBP", stats.Currency)
+	require.NotNil(t, stats.MedianPrice)
+	assert.Equal(t, 90.0, *stats.MedianPrice)
+	assert.Equal(t, 66.0, *stats.P10Price)
+	assert.Equal(t, 128.0, *stats.P90Price)
+
+	assert.Equal(t, 2, stats.SoldCount)
+	assert.Equal(t, 1, stats.RemovedCount)
+	require.NotNil(t, stats.AverageTimeToSellSeconds)
+	assert.Equal(t, (3 * time.Hour).Seconds(), *stats.AverageTimeToSellSeconds)
+	require.NotNil(t, stats.AverageTimeToDisappearSeconds)
+	assert.Equal(t, (7 * time.Hour / 3).Seconds(), *stats.AverageTimeToDisappearSeconds)
+
+	assert.Equal(t, []DailyVolume{
+		{Date: "2026-03-01", Count: 2},
+		{Date: "2026-03-02", Count: 3},
+	}, stats.ListingsPerDay)
+}
+
+func Test_ComputeSearchStats_NoObservations(t *testing.T) {
+	stats := ComputeSearchStats(1, nil)
+
+	assert.Zero(t, stats.ListingCount)
+	assert.Nil(t, stats.MedianPrice)
+	assert.Nil(t, stats.AverageTimeToSellSeconds)
+	assert.Nil(t, stats.AverageTimeToDisappearSeconds)
+	assert.Empty(t, stats.ListingsPerDay)
+}
+
+func Test_ComputeSearchStats_OnlyRemovedListings(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	removedAfter := func(l storage.SearchListing, d time.Duration) storage.SearchListing {
+		removedAt := l.FirstSeenAt.Add(d)
+		l.RemovedAt = &removedAt
+		return l
+	}
+
+	stats := ComputeSearchStats(1, []storage.SearchListing{
+		removedAfter(listing(1, 50, "GBP", day1), 2*time.Hour),
+		removedAfter(listing(2, 70, "GBP", day1), 6*time.Hour),
+	})
+
+	assert.Zero(t, stats.SoldCount)
+	assert.Equal(t, 2, stats.RemovedCount)
+	assert.Nil(t, stats.AverageTimeToSellSeconds)
+	require.NotNil(t, stats.AverageTimeToDisappearSeconds)
+	assert.Equal(t, (4 * time.Hour).Seconds(), *stats.AverageTimeToDisappearSeconds)
+}
@@ -0,0 +1,154 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+	"vinted-watcher/internal/discord"
+	"vinted-watcher/internal/vinted"
+)
+
+const (
+	maxEmbedsPerMessage = 10 // Discord limit
+	// Discord limits the combined characters of every embed in a message
+	maxEmbedCharactersPerMessage = 6000
+	// Discord shows the images of up to four embeds sharing a URL as a single gallery
+	maxGalleryImages = 4
+	// maxDescriptionLength keeps long item descriptions from dominating the channel
+	maxDescriptionLength = 300
+)
+
+// DiscordNotifier posts new items to a Discord channel webhook as embeds
+type DiscordNotifier struct {
+	webhook *discord.DiscordWebhook
+}
+
+func NewDiscordNotifier(webhookURL string) *DiscordNotifier {
+	return &DiscordNotifier{webhook: discord.NewDiscordWebhook(webhookURL)}
+}
+
+func (d *DiscordNotifier) Notify(ctx context.Context, notification Notification) error {
+	if len(notification.Items) == 0 {
+		return nil // No items to notify about
+	}
+
+	// Split items into batches if needed (Discord has a limit of 10 embeds per message)
+	batches := createDiscordBatches(notification)
+
+	delivered := make([]vinted.Item, 0, len(notification.Items))
+	for i, batch := range batches {
+		message := createDiscordMessage(notification, batch, i, len(batches))
+		if err := d.webhook.PostMessage(ctx, message); err != nil {
+			return partialDelivery(delivered, fmt.Errorf("failed to send batch %d: discord API error: %w", i+1, err))
+		}
+		delivered = append(delivered, batch...)
+	}
+
+	return nil
+}
+
+func createDiscordMessage(notification Notification, items []vinted.Item, batchNum, totalBatches int) discord.WebhookMessage {
+	content := formatDiscordMessageContent(notification, len(items), batchNum, totalBatches)
+
+	message := discord.WebhookMessage{
+		Content: content,
+		Embeds:  make([]discord.Embed, 0, len(items)),
+	}
+
+	for _, item := range items {
+		message.Embeds = append(message.Embeds, createItemEmbeds(notification.format(item))...)
+	}
+
+	return message
+}
+
+// createDiscordBatches splits the notification's items into batches whose embeds, including
+// each item's photo gallery, fit in a single message by both count and characters
+func createDiscordBatches(notification Notification) [][]vinted.Item {
+	var batches [][]vinted.Item
+	var batch []vinted.Item
+	embedCount, characterCount := 0, 0
+
+	for _, item := range notification.Items {
+		embeds := createItemEmbeds(notification.format(item))
+		itemCharacterCount := embedsLength(embeds)
+		if len(batch) > 0 && (embedCount+len(embeds) > maxEmbedsPerMessage || characterCount+itemCharacterCount > maxEmbedCharactersPerMessage) {
+			batches = append(batches, batch)
+			batch, embedCount, characterCount = nil, 0, 0
+		}
+		batch = append(batch, item)
+		embedCount += len(embeds)
+		characterCount += itemCharacterCount
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// embedsLength counts the characters Discord includes in its per-message embed limit
+func embedsLength(embeds []discord.Embed) int {
+	length := 0
+	for _, embed := range embeds {
+		length += utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+		for _, field := range embed.Fields {
+			length += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+		}
+	}
+	return length
+}
+
+func formatDiscordMessageContent(notification Notification, itemCount, batchNum, totalBatches int) string {
+	searchName := notification.Search.Name
+	if searchURL := notification.searchURL(); searchURL != "" {
+		searchName = fmt.Sprintf("[%s](%s)", searchName, searchURL)
+	}
+
+	if totalBatches == 1 {
+		return fmt.Sprintf("🔍 **%s**: %s", searchName, notification.summary(itemCount))
+	}
+
+	return fmt.Sprintf("🔍 **%s**: Batch %d/%d", searchName, batchNum+1, totalBatches)
+}
+
+func createItemEmbed(formatted formattedItem) discord.Embed {
+	embed := discord.Embed{
+		Title:       truncateTitle(formatted.Title, 256), // Discord title limit
+		Description: truncateTitle(formatted.Description, maxDescriptionLength),
+		URL:         formatted.URL,
+		Fields:      make([]discord.EmbedField, 0, len(formatted.Fields)),
+	}
+
+	for _, field := range formatted.Fields {
+		embed.Fields = append(embed.Fields, discord.EmbedField{
+			Name:   field.Name,
+			Value:  field.Value,
+			Inline: true,
+		})
+	}
+
+	// Add image if available
+	if formatted.ImageURL != "" {
+		embed.Image = discord.EmbedImage{
+			URL: formatted.ImageURL,
+		}
+	}
+
+	return embed
+}
+
+// createItemEmbeds returns the item's embed followed by an image-only embed for each further
+// photo, which Discord displays together as a gallery
+func createItemEmbeds(formatted formattedItem) []discord.Embed {
+	embeds := []discord.Embed{createItemEmbed(formatted)}
+
+	for _, imageURL := range formatted.GalleryURLs {
+		if len(embeds) == maxGalleryImages || formatted.URL == "" {
+			break
+		}
+		embeds = append(embeds, discord.Embed{URL: formatted.URL, Image: discord.EmbedImage{URL: imageURL}})
+	}
+
+	return embeds
+}
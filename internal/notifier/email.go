@@ -0,0 +1,249 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/storage"
+	"vinted-watcher/internal/vinted"
+)
+
+const (
+	EmailToOption       = "to"
+	EmailScheduleOption = "schedule"
+
+	EmailScheduleHourly = "hourly"
+	EmailScheduleDaily  = "daily"
+
+	digestColumns = 3
+)
+
+// Mailer sends an HTML email
+type Mailer interface {
+	Send(ctx context.Context, to []string, subject, htmlBody string) error
+}
+
+// EmailDigestNotifier queues new items so they are sent as a single email digest on a schedule.
+// Items are persisted so a restart doesn't lose a pending digest; DigestSender delivers them.
+type EmailDigestNotifier struct {
+	store      storage.DigestStorage
+	recipients string
+	period     time.Duration
+}
+
+func newEmailDigestNotifierFromTarget(target domain.NotificationTarget, deps Dependencies) (*EmailDigestNotifier, error) {
+	if deps.Mailer == nil {
+		return nil, fmt.Errorf("email notification target requires SMTP to be configured")
+	}
+
+	recipients := normaliseRecipients(target.Options[EmailToOption])
+	if recipients == "" {
+		return nil, fmt.Errorf("email notification target requires a %q option", EmailToOption)
+	}
+
+	period, err := parseDigestSchedule(target.Options[EmailScheduleOption])
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailDigestNotifier{store: deps.DigestStore, recipients: recipients, period: period}, nil
+}
+
+func (e *EmailDigestNotifier) Notify(ctx context.Context, notification Notification) error {
+	if len(notification.Items) == 0 {
+		return nil // No items to notify about
+	}
+
+	// Digests list items that are still for sale
+	if notification.IsSold() {
+		return nil
+	}
+
+	if e.store == nil {
+		return fmt.Errorf("email digests require a digest store")
+	}
+
+	if err := e.store.EnqueueDigestItems(ctx, e.recipients, e.period, notification.Search, notification.Items, notification.PreviousPrices, notification.DealScores); err != nil {
+		return fmt.Errorf("failed to queue digest items: %w", err)
+	}
+
+	slog.Info("queued items for email digest", "search_id", notification.Search.ID, "count", len(notification.Items))
+	return nil
+}
+
+func parseDigestSchedule(schedule string) (time.Duration, error) {
+	switch schedule {
+	case EmailScheduleHourly:
+		return time.Hour, nil
+	case EmailScheduleDaily, "":
+		return 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unknown email digest schedule %q, expected %q or %q", schedule, EmailScheduleHourly, EmailScheduleDaily)
+	}
+}
+
+// normaliseRecipients turns a comma separated address list into a canonical form so the same
+// recipients always share a digest
+func normaliseRecipients(recipients string) string {
+	addresses := make([]string, 0)
+	for _, address := range strings.Split(recipients, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	slices.Sort(addresses)
+	return strings.Join(slices.Compact(addresses), ",")
+}
+
+// DigestSender periodically emails the pending digest items whose schedule has elapsed
+type DigestSender struct {
+	store  storage.DigestStorage
+	mailer Mailer
+	now    func() time.Time
+}
+
+func NewDigestSender(store storage.DigestStorage, mailer Mailer) *DigestSender {
+	return &DigestSender{
+		store:  store,
+		mailer: mailer,
+		now:    time.Now,
+	}
+}
+
+// Run sends due digests on every tick until the context is cancelled
+func (d *DigestSender) Run(ctx context.Context, checkInterval time.Duration) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := d.SendDue(ctx); err != nil {
+				slog.Error("Error sending email digests", "error", err)
+			}
+		case <-ctx.Done():
+			slog.Info("Stopping email digest sender...")
+			return
+		}
+	}
+}
+
+// SendDue emails every digest whose oldest pending item has waited for at least the digest period
+func (d *DigestSender) SendDue(ctx context.Context) error {
+	pending, err := d.store.GetPendingDigestItems(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pending digest items: %w", err)
+	}
+
+	type digestKey struct {
+		recipients string
+		period     time.Duration
+	}
+
+	digests := make(map[digestKey][]storage.DigestItem)
+	order := make([]digestKey, 0)
+	for _, item := range pending {
+		key := digestKey{recipients: item.Recipients, period: item.Period}
+		if _, ok := digests[key]; !ok {
+			order = append(order, key)
+		}
+		digests[key] = append(digests[key], item)
+	}
+
+	var errs []error
+	now := d.now()
+	for _, key := range order {
+		items := digests[key]
+		if now.Sub(items[0].QueuedAt) < key.period {
+			continue
+		}
+
+		if err := d.send(ctx, strings.Split(key.recipients, ","), items); err != nil {
+			errs = append(errs, fmt.Errorf("digest for %s: %w", key.recipients, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (d *DigestSender) send(ctx context.Context, to []string, items []storage.DigestItem) error {
+	body, err := renderDigest(items)
+	if err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	subject := fmt.Sprintf("Vinted digest: %d new item(s)", len(items))
+	if err := d.mailer.Send(ctx, to, subject, body); err != nil {
+		return fmt.Errorf("failed to send digest: %w", err)
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	return d.store.DeleteDigestItems(ctx, ids)
+}
+
+type digestSearch struct {
+	Name string
+	Rows [][]formattedItem
+}
+
+var digestTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+{{range .}}
+<h2>🔍 {{.Name}}</h2>
+<table cellpadding="8" cellspacing="0">
+{{range .Rows}}<tr>
+{{range .}}<td valign="top" width="200">
+{{if .ImageURL}}<a href="{{.URL}}"><img src="{{.ImageURL}}" alt="{{.Title}}" width="180" style="display: block;"></a>{{end}}
+<p><a href="{{.URL}}">{{.Title}}</a></p>
+{{range .Fields}}<div>{{.Name}}: {{.Value}}</div>
+{{end}}</td>
+{{end}}</tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+func renderDigest(items []storage.DigestItem) (string, error) {
+	searches := make([]*digestSearch, 0)
+	bySearch := make(map[int]*digestSearch)
+	itemsBySearch := make(map[int][]formattedItem)
+
+	for _, item := range items {
+		if _, ok := bySearch[item.SearchID]; !ok {
+			bySearch[item.SearchID] = &digestSearch{Name: item.SearchName}
+			searches = append(searches, bySearch[item.SearchID])
+		}
+
+		var notification Notification
+		if item.PreviousPrice != nil {
+			notification.PreviousPrices = map[int64]vinted.Price{item.Item.ID: *item.PreviousPrice}
+		}
+		if item.DealScore != nil {
+			notification.DealScores = map[int64]float64{item.Item.ID: *item.DealScore}
+		}
+		itemsBySearch[item.SearchID] = append(itemsBySearch[item.SearchID], notification.format(item.Item))
+	}
+
+	for searchID, search := range bySearch {
+		search.Rows = createItemBatches(itemsBySearch[searchID], digestColumns)
+	}
+
+	var buf bytes.Buffer
+	if err := digestTemplate.Execute(&buf, searches); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
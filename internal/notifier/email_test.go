@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/storage"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sentEmail struct {
+	to      []string
+	subject string
+	body    string
+}
+
+type fakeMailer struct {
+	sent []sentEmail
+	err  error
+}
+
+func (f *fakeMailer) Send(ctx context.Context, to []string, subject, htmlBody string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, sentEmail{to: to, subject: subject, body: htmlBody})
+	return nil
+}
+
+func setupDigestTest(t *testing.T) (*storage.DB, domain.SavedSearch) {
+	t.Helper()
+
+	db, err := storage.NewDB(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	searchID, err := db.CreateSearch(context.Background(), domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	return db, domain.SavedSearch{ID: searchID, Name: "barbour"}
+}
+
+func Test_EmailDigest_QueuesAndSendsWhenDue(t *testing.T) {
+	db, search := setupDigestTest(t)
+	ctx := context.Background()
+
+	n, err := New(domain.NotificationTarget{
+		Type: domain.NotificationTargetEmail,
+		Options: map[string]string{
+			EmailToOption:       "b@example.com, a@example.com",
+			EmailScheduleOption: EmailScheduleHourly,
+		},
+	}, Dependencies{DigestStore: db, Mailer: &fakeMailer{}})
+	require.NoError(t, err)
+
+	second := testItem()
+	second.ID = 2
+	second.Title = "Beaufort"
+	dealScores := map[int64]float64{second.ID: 12.5}
+	require.NoError(t, n.Notify(ctx, Notification{Search: search, Items: []vinted.Item{testItem(), second}, DealScores: dealScores}))
+
+	mailer := &fakeMailer{}
+	sender := NewDigestSender(db, mailer)
+
+	// Not due yet
+	require.NoError(t, sender.SendDue(ctx))
+	assert.Empty(t, mailer.sent)
+
+	sender.now = func() time.Time { return time.Now().Add(time.Hour + time.Minute) }
+	require.NoError(t, sender.SendDue(ctx))
+
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, mailer.sent[0].to)
+	assert.Equal(t, "Vinted digest: 2 new item(s)", mailer.sent[0].subject)
+	assert.Contains(t, mailer.sent[0].body, "🔍 barbour")
+	assert.Contains(t, mailer.sent[0].body, "Barbour &lt;Bedale&gt; jacket")
+	assert.Contains(t, mailer.sent[0].body, `<img src="https://images.vinted.net/1.jpg"`)
+	assert.Contains(t, mailer.sent[0].body, "💰 Price: £40.0")
+	assert.Contains(t, mailer.sent[0].body, "🎯 Deal: cheaper than 88% of similar listings")
+
+	pending, err := db.GetPendingDigestItems(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func Test_EmailDigest_KeepsItemsWhenSendFails(t *testing.T) {
+	db, search := setupDigestTest(t)
+	ctx := context.Background()
+
+	n, err := New(domain.NotificationTarget{
+		Type:    domain.NotificationTargetEmail,
+		Options: map[string]string{EmailToOption: "a@example.com"},
+	}, Dependencies{DigestStore: db, Mailer: &fakeMailer{}})
+	require.NoError(t, err)
+	require.NoError(t, n.Notify(ctx, Notification{Search: search, Items: []vinted.Item{testItem()}}))
+
+	sender := NewDigestSender(db, &fakeMailer{err: errors.New("connection refused")})
+	sender.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+
+	assert.Error(t, sender.SendDue(ctx))
+
+	pending, err := db.GetPendingDigestItems(ctx)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+}
+
+func Test_EmailTargetValidation(t *testing.T) {
+	deps := Dependencies{Mailer: &fakeMailer{}}
+	valid := domain.NotificationTarget{
+		Type:    domain.NotificationTargetEmail,
+		Options: map[string]string{EmailToOption: "a@example.com"},
+	}
+
+	assert.NoError(t, Validate([]domain.NotificationTarget{valid}, deps))
+	assert.Error(t, Validate([]domain.NotificationTarget{valid}, Dependencies{}), "email targets should be rejected when SMTP is not configured")
+	assert.Error(t, Validate([]domain.NotificationTarget{{Type: domain.NotificationTargetEmail}}, deps))
+	assert.Error(t, Validate([]domain.NotificationTarget{{
+		Type:    domain.NotificationTargetEmail,
+		Options: map[string]string{EmailToOption: "a@example.com", EmailScheduleOption: "weekly"},
+	}}, deps))
+}
@@ -0,0 +1,221 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"vinted-watcher/internal/vinted"
+)
+
+// itemField is a labelled value shown alongside an item in a notification
+type itemField struct {
+	Name  string
+	Value string
+}
+
+// formattedItem is the channel-agnostic presentation of an item, shared by every notifier
+type formattedItem struct {
+	Title    string
+	URL      string
+	ImageURL string
+	// GalleryURLs holds the item's other photos when its details are known
+	GalleryURLs []string
+	Description string
+	Fields      []itemField
+}
+
+func formatItem(item vinted.Item) formattedItem {
+	formatted := formattedItem{
+		Title:    item.Title,
+		URL:      item.URL,
+		ImageURL: item.Photo.URL,
+		Fields: []itemField{
+			{Name: "💰 Price", Value: formatPrice(item.Price)},
+		},
+	}
+
+	// Add total including the buyer protection fee if available
+	if item.TotalItemPrice.Amount != "" {
+		formatted.Fields = append(formatted.Fields, itemField{Name: "🧾 Total incl. fees", Value: formatPrice(vinted.Price(item.TotalItemPrice))})
+	}
+
+	// Add size field if available
+	if item.SizeTitle != "" {
+		formatted.Fields = append(formatted.Fields, itemField{Name: "📏 Size", Value: item.SizeTitle})
+	}
+
+	// Add brand field if available
+	if item.BrandTitle != "" {
+		formatted.Fields = append(formatted.Fields, itemField{Name: "🏷️ Brand", Value: item.BrandTitle})
+	}
+
+	// Add condition field if available
+	if item.Status != "" {
+		formatted.Fields = append(formatted.Fields, itemField{Name: "✨ Condition", Value: item.Status})
+	}
+
+	// Sellers' ratings are only known once the item has been enriched
+	if item.User.FeedbackCount > 0 {
+		formatted.Fields = append(formatted.Fields, itemField{Name: "⭐ Seller", Value: formatSellerRating(item.User)})
+	}
+
+	if item.Details != nil {
+		formatDetails(&formatted, *item.Details)
+	}
+
+	return formatted
+}
+
+// formatDetails adds what the item detail endpoint reports to the formatted item
+func formatDetails(formatted *formattedItem, details vinted.ListingDetails) {
+	formatted.Description = details.Description
+
+	for _, photo := range details.Photos {
+		photoURL := photo.FullSizeURL
+		if photoURL == "" {
+			photoURL = photo.URL
+		}
+		if photoURL == "" || photoURL == formatted.ImageURL {
+			continue
+		}
+		if formatted.ImageURL == "" {
+			formatted.ImageURL = photoURL
+			continue
+		}
+		formatted.GalleryURLs = append(formatted.GalleryURLs, photoURL)
+	}
+
+	colours := make([]string, 0, 2)
+	for _, colour := range []string{details.Color1, details.Color2} {
+		if colour != "" {
+			colours = append(colours, colour)
+		}
+	}
+	if len(colours) > 0 {
+		formatted.Fields = append(formatted.Fields, itemField{Name: "🎨 Colour", Value: strings.Join(colours, ", ")})
+	}
+
+	measurements := make([]string, 0, 2)
+	if details.MeasurementLength > 0 {
+		measurements = append(measurements, fmt.Sprintf("length %g cm", details.MeasurementLength))
+	}
+	if details.MeasurementWidth > 0 {
+		measurements = append(measurements, fmt.Sprintf("width %g cm", details.MeasurementWidth))
+	}
+	if len(measurements) > 0 {
+		formatted.Fields = append(formatted.Fields, itemField{Name: "📐 Measurements", Value: strings.Join(measurements, ", ")})
+	}
+}
+
+// formatSellerRating describes a seller's rating out of five, e.g. "4.8/5 (120 reviews)"
+func formatSellerRating(seller vinted.User) string {
+	return fmt.Sprintf("%.1f/5 (%d reviews)", seller.FeedbackReputation*5, seller.FeedbackCount)
+}
+
+// format formats the item, replacing its price with the reduction for price drop notifications
+func (n Notification) format(item vinted.Item) formattedItem {
+	formatted := formatItem(item)
+
+	if previous, ok := n.PreviousPrices[item.ID]; ok {
+		formatted.Fields[0] = itemField{
+			Name:  "📉 Price dropped",
+			Value: fmt.Sprintf("from %s to %s", formatPrice(previous), formatPrice(item.Price)),
+		}
+	}
+
+	if timeToSell, ok := n.TimesToSell[item.ID]; ok {
+		formatted.Fields = append(formatted.Fields, itemField{Name: "✅ Sold", Value: fmt.Sprintf("after %s", formatDuration(timeToSell))})
+	}
+
+	if score, ok := n.DealScores[item.ID]; ok {
+		formatted.Fields = append(formatted.Fields, itemField{Name: "🎯 Deal", Value: formatDealScore(score)})
+	}
+
+	return formatted
+}
+
+// summary describes what the notification's items are, e.g. "2 new item(s) found"
+func (n Notification) summary(itemCount int) string {
+	if n.IsPriceDrop() {
+		return fmt.Sprintf("%d price drop(s)", itemCount)
+	}
+	if n.IsSold() {
+		return fmt.Sprintf("%d watched item(s) sold", itemCount)
+	}
+	return fmt.Sprintf("%d new item(s) found", itemCount)
+}
+
+// searchURL links back to the search on the Vinted website, or is empty if it can't be built
+func (n Notification) searchURL() string {
+	if n.Search.SearchParams == nil {
+		return ""
+	}
+
+	webURL, err := n.Search.SearchParams.ToWebURL()
+	if err != nil {
+		return ""
+	}
+	return webURL
+}
+
+// formatDealScore describes a price percentile, e.g. "cheaper than 85% of similar listings"
+func formatDealScore(score float64) string {
+	return fmt.Sprintf("cheaper than %.0f%% of similar listings", 100-score)
+}
+
+// formatDuration describes a duration to the nearest minute, e.g. "2d 3h" or "45m"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days, hours, minutes := int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour), int(d%time.Hour/time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+func formatPrice(price vinted.Price) string {
+	if price.Amount == "" || price.CurrencyCode == "" {
+		return "Price not available"
+	}
+
+	// Handle different currency formats
+	switch price.CurrencyCode {
+	case "EUR":
+		return fmt.Sprintf("€%s", price.Amount)
+	case "USD":
+		return fmt.Sprintf("$%s", price.Amount)
+	case "GBP":
+		return fmt.Sprintf("£%s", price.Amount)
+	default:
+		return fmt.Sprintf("%s %s", price.Amount, price.CurrencyCode)
+	}
+}
+
+// truncateTitle shortens the title to maxLength characters, cutting on rune boundaries as
+// notification services count characters rather than bytes
+func truncateTitle(title string, maxLength int) string {
+	runes := []rune(title)
+	if len(runes) <= maxLength {
+		return title
+	}
+	return string(runes[:maxLength-3]) + "..."
+}
+
+func createItemBatches[T any](items []T, batchSize int) [][]T {
+	var batches [][]T
+
+	for i := 0; i < len(items); i += batchSize {
+		end := i + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		batches = append(batches, items[i:end])
+	}
+
+	return batches
+}
@@ -0,0 +1,80 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/gotify"
+)
+
+const (
+	GotifyTokenOption    = "token"
+	GotifyPriorityOption = "priority"
+
+	defaultGotifyPriority = 5
+)
+
+// GotifyNotifier posts each new item to a Gotify server as a markdown message
+type GotifyNotifier struct {
+	app      *gotify.GotifyApp
+	priority int
+}
+
+func newGotifyNotifierFromTarget(target domain.NotificationTarget) (*GotifyNotifier, error) {
+	token := target.Options[GotifyTokenOption]
+	if target.URL == "" || token == "" {
+		return nil, fmt.Errorf("gotify notification target requires a server url and a %q option", GotifyTokenOption)
+	}
+
+	priority := defaultGotifyPriority
+	if value := target.Options[GotifyPriorityOption]; value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gotify priority %q: %w", value, err)
+		}
+		priority = parsed
+	}
+
+	return &GotifyNotifier{
+		app:      gotify.NewGotifyApp(target.URL, token),
+		priority: priority,
+	}, nil
+}
+
+func (g *GotifyNotifier) Notify(ctx context.Context, notification Notification) error {
+	for i, item := range notification.Items {
+		formatted := notification.format(item)
+		message := gotify.NewMarkdownMessage(
+			truncateTitle(formatted.Title, 256),
+			createGotifyMarkdown(formatted, notification.Search),
+			g.priority,
+			formatted.URL,
+		)
+
+		if err := g.app.PostMessage(ctx, message); err != nil {
+			return partialDelivery(notification.Items[:i], fmt.Errorf("failed to send item %d: gotify error: %w", item.ID, err))
+		}
+	}
+
+	return nil
+}
+
+func createGotifyMarkdown(formatted formattedItem, search domain.SavedSearch) string {
+	lines := []string{fmt.Sprintf("🔍 **%s**", search.Name), ""}
+
+	if formatted.ImageURL != "" {
+		lines = append(lines, fmt.Sprintf("![%s](%s)", formatted.Title, formatted.ImageURL), "")
+	}
+
+	for _, field := range formatted.Fields {
+		lines = append(lines, fmt.Sprintf("**%s**: %s  ", field.Name, field.Value))
+	}
+
+	if formatted.URL != "" {
+		lines = append(lines, "", fmt.Sprintf("[Open on Vinted](%s)", formatted.URL))
+	}
+
+	return strings.Join(lines, "\n")
+}
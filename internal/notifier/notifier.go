@@ -0,0 +1,176 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/storage"
+	"vinted-watcher/internal/vinted"
+)
+
+// Notification describes a set of new items found for a saved search, price drops on items
+// that had already been seen, or watched items that have since sold
+type Notification struct {
+	Search domain.SavedSearch
+	Items  []vinted.Item
+	// PreviousPrices is set for price drop notifications, mapping each item's ID to the price
+	// it was reduced from
+	PreviousPrices map[int64]vinted.Price
+	// DealScores maps item IDs to the percentile of the item's price among comparable
+	// listings, for items where it is known
+	DealScores map[int64]float64
+	// TimesToSell is set for sold notifications, mapping each item's ID to how long it was
+	// listed before it sold
+	TimesToSell map[int64]time.Duration
+	// DeliveryIDs maps item IDs to the outbox entry they were queued as, which stays the same
+	// across retries so receivers can drop duplicate deliveries
+	DeliveryIDs map[int64]int
+}
+
+// IsPriceDrop reports whether the notification announces price drops rather than new items
+func (n Notification) IsPriceDrop() bool {
+	return n.PreviousPrices != nil
+}
+
+// IsSold reports whether the notification announces that watched items have sold
+func (n Notification) IsSold() bool {
+	return n.TimesToSell != nil
+}
+
+// Notifier delivers notifications to a single channel such as a Discord webhook
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// PartialDeliveryError is returned by notifiers that send a notification in several requests
+// when a request fails after some items were already delivered, so only the rest are retried
+type PartialDeliveryError struct {
+	// Delivered holds the IDs of the items that were sent before the failure
+	Delivered []int64
+	Err       error
+}
+
+func (e *PartialDeliveryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialDeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// partialDelivery wraps err in a PartialDeliveryError if any items were delivered before it
+func partialDelivery(delivered []vinted.Item, err error) error {
+	if len(delivered) == 0 {
+		return err
+	}
+
+	ids := make([]int64, 0, len(delivered))
+	for _, item := range delivered {
+		ids = append(ids, item.ID)
+	}
+	return &PartialDeliveryError{Delivered: ids, Err: err}
+}
+
+// Dependencies are the shared services that stateful notifiers need
+type Dependencies struct {
+	// DigestStore persists items waiting for the next email digest
+	DigestStore storage.DigestStorage
+	// Mailer sends email digests. Email targets are rejected when it is not configured, as
+	// their digests would never be sent.
+	Mailer Mailer
+}
+
+// New creates the notifier for a notification target
+func New(target domain.NotificationTarget, deps Dependencies) (Notifier, error) {
+	switch target.Type {
+	case domain.NotificationTargetDiscord:
+		if target.URL == "" {
+			return nil, fmt.Errorf("discord notification target requires a webhook url")
+		}
+		return NewDiscordNotifier(target.URL), nil
+	case domain.NotificationTargetSlack:
+		if target.URL == "" {
+			return nil, fmt.Errorf("slack notification target requires a webhook url")
+		}
+		return NewSlackNotifier(target.URL), nil
+	case domain.NotificationTargetTelegram:
+		return newTelegramNotifierFromTarget(target)
+	case domain.NotificationTargetEmail:
+		return newEmailDigestNotifierFromTarget(target, deps)
+	case domain.NotificationTargetWebhook:
+		return newWebhookNotifierFromTarget(target)
+	case domain.NotificationTargetNtfy:
+		return newNtfyNotifierFromTarget(target)
+	case domain.NotificationTargetGotify:
+		return newGotifyNotifierFromTarget(target)
+	default:
+		return nil, fmt.Errorf("unknown notification target type: %q", target.Type)
+	}
+}
+
+// Validate checks that every target can be turned into a notifier with the given dependencies
+func Validate(targets []domain.NotificationTarget, deps Dependencies) error {
+	var errs []error
+	for i, target := range targets {
+		if _, err := New(target, deps); err != nil {
+			errs = append(errs, fmt.Errorf("notification target %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Registry resolves the notifiers for a search, reusing notifiers for targets it has seen before.
+// Searches without their own targets are routed to the default targets.
+type Registry struct {
+	defaults  []domain.NotificationTarget
+	deps      Dependencies
+	mu        sync.Mutex
+	notifiers map[string]Notifier
+}
+
+func NewRegistry(defaults []domain.NotificationTarget, deps Dependencies) *Registry {
+	return &Registry{
+		defaults:  defaults,
+		deps:      deps,
+		notifiers: make(map[string]Notifier),
+	}
+}
+
+// Validate checks that every target can be turned into a notifier by this registry
+func (r *Registry) Validate(targets []domain.NotificationTarget) error {
+	return Validate(targets, r.deps)
+}
+
+// TargetsFor returns the targets that new items for the search should be sent to
+func (r *Registry) TargetsFor(search domain.SavedSearch) []domain.NotificationTarget {
+	if len(search.NotificationTargets) > 0 {
+		return search.NotificationTargets
+	}
+	return r.defaults
+}
+
+func (r *Registry) get(target domain.NotificationTarget) (Notifier, error) {
+	key, err := json.Marshal(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification target: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n, ok := r.notifiers[string(key)]; ok {
+		return n, nil
+	}
+
+	n, err := New(target, r.deps)
+	if err != nil {
+		return nil, err
+	}
+
+	r.notifiers[string(key)] = n
+	return n, nil
+}
@@ -0,0 +1,242 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testItem() vinted.Item {
+	return vinted.Item{
+		ID:         1,
+		Title:      "Barbour <Bedale> jacket",
+		URL:        "https://www.vinted.co.uk/items/1",
+		Price:      vinted.Price{Amount: "40.0", CurrencyCode: "GBP"},
+		SizeTitle:  "M",
+		BrandTitle: "Barbour",
+		Photo:      vinted.ItemPhoto{URL: "https://images.vinted.net/1.jpg"},
+	}
+}
+
+func Test_Registry_RoutesSearchTargetsOrDefaults(t *testing.T) {
+	defaults := []domain.NotificationTarget{{Type: domain.NotificationTargetDiscord, URL: "https://discord.example/default"}}
+	registry := NewRegistry(defaults, Dependencies{})
+
+	assert.Equal(t, defaults, registry.TargetsFor(domain.SavedSearch{}))
+
+	search := domain.SavedSearch{NotificationTargets: []domain.NotificationTarget{
+		{Type: domain.NotificationTargetSlack, URL: "https://hooks.slack.example/kids"},
+		{Type: domain.NotificationTargetDiscord, URL: "https://discord.example/kids"},
+	}}
+	assert.Equal(t, search.NotificationTargets, registry.TargetsFor(search))
+
+	// Notifiers are reused for the same target
+	n, err := registry.get(search.NotificationTargets[0])
+	require.NoError(t, err)
+	assert.IsType(t, &SlackNotifier{}, n)
+
+	again, err := registry.get(search.NotificationTargets[0])
+	require.NoError(t, err)
+	assert.Same(t, n, again)
+}
+
+func Test_Validate(t *testing.T) {
+	assert.NoError(t, Validate([]domain.NotificationTarget{{Type: domain.NotificationTargetDiscord, URL: "https://discord.example"}}, Dependencies{}))
+	assert.Error(t, Validate([]domain.NotificationTarget{{Type: domain.NotificationTargetDiscord}}, Dependencies{}))
+	assert.Error(t, Validate([]domain.NotificationTarget{{Type: "carrier-pigeon", URL: "https://example"}}, Dependencies{}))
+}
+
+func Test_CreateSlackMessage_ReusesItemFormatting(t *testing.T) {
+	item := testItem()
+
+	message := createSlackMessage(Notification{Search: domain.SavedSearch{Name: "barbour"}}, []vinted.Item{item}, 0, 1)
+
+	assert.Equal(t, "🔍 *barbour*: 1 new item(s) found", message.Text)
+	require.Len(t, message.Blocks, 3)
+
+	itemBlock := message.Blocks[2]
+	assert.Equal(t, "*<https://www.vinted.co.uk/items/1|Barbour &lt;Bedale&gt; jacket>*", itemBlock.Text.Text)
+	require.Len(t, itemBlock.Fields, 3)
+	assert.Equal(t, "*💰 Price*\n£40.0", itemBlock.Fields[0].Text)
+	assert.Equal(t, "*📏 Size*\nM", itemBlock.Fields[1].Text)
+	assert.Equal(t, "*🏷️ Brand*\nBarbour", itemBlock.Fields[2].Text)
+	assert.Equal(t, "https://images.vinted.net/1.jpg", itemBlock.Accessory.ImageURL)
+
+	embed := createItemEmbed(formatItem(item))
+	require.Len(t, embed.Fields, 3)
+	assert.Equal(t, "£40.0", embed.Fields[0].Value)
+}
+
+func Test_FormatItem_ShowsTotalWithFees(t *testing.T) {
+	item := testItem()
+	item.TotalItemPrice = vinted.TotalItemPrice{Amount: "42.70", CurrencyCode: "GBP"}
+
+	formatted := formatItem(item)
+
+	require.Len(t, formatted.Fields, 4)
+	assert.Equal(t, itemField{Name: "💰 Price", Value: "£40.0"}, formatted.Fields[0])
+	assert.Equal(t, itemField{Name: "🧾 Total incl. fees", Value: "£42.70"}, formatted.Fields[1])
+}
+
+func Test_Notification_FormatsPriceDrops(t *testing.T) {
+	item := testItem()
+	notification := Notification{
+		Search:         domain.SavedSearch{Name: "barbour"},
+		Items:          []vinted.Item{item},
+		PreviousPrices: map[int64]vinted.Price{item.ID: {Amount: "55.0", CurrencyCode: "GBP"}},
+	}
+
+	formatted := notification.format(item)
+	assert.Equal(t, itemField{Name: "📉 Price dropped", Value: "from £55.0 to £40.0"}, formatted.Fields[0])
+
+	message := createDiscordMessage(notification, notification.Items, 0, 1)
+	assert.Equal(t, "🔍 **barbour**: 1 price drop(s)", message.Content)
+	assert.Equal(t, "from £55.0 to £40.0", message.Embeds[0].Fields[0].Value)
+
+	// New item notifications are unaffected
+	assert.Equal(t, itemField{Name: "💰 Price", Value: "£40.0"}, Notification{}.format(item).Fields[0])
+}
+
+func Test_Notification_FormatsDealScores(t *testing.T) {
+	item := testItem()
+	notification := Notification{DealScores: map[int64]float64{item.ID: 12.5}}
+
+	formatted := notification.format(item)
+
+	assert.Equal(t, itemField{Name: "🎯 Deal", Value: "cheaper than 88% of similar listings"}, formatted.Fields[len(formatted.Fields)-1])
+}
+
+func Test_Notification_FormatsSoldItems(t *testing.T) {
+	item := testItem()
+	notification := Notification{
+		Search:      domain.SavedSearch{Name: "barbour"},
+		Items:       []vinted.Item{item},
+		TimesToSell: map[int64]time.Duration{item.ID: 50*time.Hour + 20*time.Minute},
+	}
+
+	formatted := notification.format(item)
+	assert.Equal(t, itemField{Name: "✅ Sold", Value: "after 2d 2h"}, formatted.Fields[len(formatted.Fields)-1])
+
+	message := createDiscordMessage(notification, notification.Items, 0, 1)
+	assert.Equal(t, "🔍 **barbour**: 1 watched item(s) sold", message.Content)
+
+	assert.Equal(t, "3h 5m", formatDuration(3*time.Hour+5*time.Minute))
+	assert.Equal(t, "45m", formatDuration(45*time.Minute))
+}
+
+func Test_FormatItem_ShowsDetails(t *testing.T) {
+	item := testItem()
+	item.Status = "Very good"
+	item.User = vinted.User{FeedbackReputation: 0.96, FeedbackCount: 120}
+	item.Details = &vinted.ListingDetails{
+		Description:       "Waxed jacket",
+		Color1:            "Olive",
+		Color2:            "Brown",
+		MeasurementLength: 70,
+		MeasurementWidth:  55.5,
+		Photos: []vinted.ItemPhoto{
+			{URL: "https://images.vinted.net/1.jpg"},
+			{URL: "https://images.vinted.net/1-small.jpg", FullSizeURL: "https://images.vinted.net/2.jpg"},
+			{URL: "https://images.vinted.net/3.jpg"},
+		},
+	}
+
+	formatted := formatItem(item)
+
+	assert.Equal(t, "Waxed jacket", formatted.Description)
+	assert.Equal(t, "https://images.vinted.net/1.jpg", formatted.ImageURL)
+	assert.Equal(t, []string{"https://images.vinted.net/2.jpg", "https://images.vinted.net/3.jpg"}, formatted.GalleryURLs)
+	assert.Contains(t, formatted.Fields, itemField{Name: "✨ Condition", Value: "Very good"})
+	assert.Contains(t, formatted.Fields, itemField{Name: "⭐ Seller", Value: "4.8/5 (120 reviews)"})
+	assert.Contains(t, formatted.Fields, itemField{Name: "🎨 Colour", Value: "Olive, Brown"})
+	assert.Contains(t, formatted.Fields, itemField{Name: "📐 Measurements", Value: "length 70 cm, width 55.5 cm"})
+}
+
+func Test_CreateDiscordMessage_ShowsPhotoGallery(t *testing.T) {
+	newItem := func(id int64, photoCount int) vinted.Item {
+		item := testItem()
+		item.ID = id
+		item.Details = &vinted.ListingDetails{Description: "Waxed jacket"}
+		for i := 0; i < photoCount; i++ {
+			item.Details.Photos = append(item.Details.Photos, vinted.ItemPhoto{URL: fmt.Sprintf("https://images.vinted.net/%d-%d.jpg", id, i)})
+		}
+		return item
+	}
+
+	notification := Notification{Items: []vinted.Item{newItem(1, 6), newItem(2, 1), newItem(3, 4), newItem(4, 4)}}
+
+	message := createDiscordMessage(notification, notification.Items[:1], 0, 1)
+	require.Len(t, message.Embeds, maxGalleryImages)
+	assert.Equal(t, "Waxed jacket", message.Embeds[0].Description)
+	for _, embed := range message.Embeds[1:] {
+		assert.Equal(t, "https://www.vinted.co.uk/items/1", embed.URL, "gallery embeds must share the item's URL")
+		assert.NotEmpty(t, embed.Image.URL)
+	}
+
+	// 4 + 2 + 4 embeds fit in the first message, leaving the last item for a second
+	batches := createDiscordBatches(notification)
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[0], 3)
+	assert.Len(t, batches[1], 1)
+}
+
+func Test_CreateDiscordBatches_StaysWithinCharacterLimit(t *testing.T) {
+	items := make([]vinted.Item, 0, 10)
+	for i := int64(1); i <= 10; i++ {
+		item := testItem()
+		item.ID = i
+		item.Title = strings.Repeat("Barbour Bedale wax jacket ", 20)
+		item.Status = "Very good"
+		item.Details = &vinted.ListingDetails{
+			Description:       strings.Repeat("Worn a handful of times, rewaxed last winter. ", 40),
+			Color1:            "Olive",
+			MeasurementLength: 70,
+		}
+		items = append(items, item)
+	}
+	notification := Notification{Items: items}
+
+	batches := createDiscordBatches(notification)
+	require.Greater(t, len(batches), 1)
+
+	batched := 0
+	for i, batch := range batches {
+		message := createDiscordMessage(notification, batch, i, len(batches))
+		assert.LessOrEqual(t, embedsLength(message.Embeds), maxEmbedCharactersPerMessage)
+		batched += len(batch)
+	}
+	assert.Equal(t, len(items), batched)
+}
+
+func Test_TruncateTitle_CutsOnRuneBoundaries(t *testing.T) {
+	assert.Equal(t, "short", truncateTitle("short", 10))
+	assert.Equal(t, "Veste cirée", truncateTitle("Veste cirée", 11))
+	assert.Equal(t, "Veste ci...", truncateTitle("Veste cirée Barbour", 11))
+	assert.Equal(t, "éééé...", truncateTitle("ééééééééé", 7))
+	assert.Equal(t, "🧥🧥...", truncateTitle("🧥🧥🧥🧥🧥🧥", 5))
+
+	truncated := truncateTitle(strings.Repeat("é", 400), maxDescriptionLength)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.Equal(t, maxDescriptionLength, utf8.RuneCountInString(truncated))
+}
+
+func Test_Notification_LinksBackToSearch(t *testing.T) {
+	notification := Notification{Search: domain.SavedSearch{
+		Name:         "barbour",
+		SearchParams: &domain.SearchParams{SearchText: "barbour", Domain: "www.vinted.fr"},
+	}}
+	searchURL := "https://www.vinted.fr/catalog?order=newest_first&search_text=barbour"
+
+	assert.Equal(t, "🔍 **[barbour]("+searchURL+")**: 1 new item(s) found", createDiscordMessage(notification, []vinted.Item{testItem()}, 0, 1).Content)
+	assert.Equal(t, "🔍 *<"+searchURL+"|barbour>*: 1 new item(s) found", createSlackMessage(notification, []vinted.Item{testItem()}, 0, 1).Text)
+
+	// Searches without parameters aren't linked
+	assert.Empty(t, Notification{Search: domain.SavedSearch{Name: "barbour"}}.searchURL())
+}
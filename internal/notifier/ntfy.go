@@ -0,0 +1,78 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/ntfy"
+	"vinted-watcher/internal/vinted"
+)
+
+const (
+	NtfyTokenOption    = "token"
+	NtfyPriorityOption = "priority"
+)
+
+var ntfyPriorities = map[string]bool{
+	"1": true, "2": true, "3": true, "4": true, "5": true,
+	"min": true, "low": true, "default": true, "high": true, "max": true, "urgent": true,
+}
+
+// NtfyNotifier publishes each new item to an ntfy topic with its photo attached
+type NtfyNotifier struct {
+	topic    *ntfy.NtfyTopic
+	priority string
+}
+
+func newNtfyNotifierFromTarget(target domain.NotificationTarget) (*NtfyNotifier, error) {
+	if target.URL == "" {
+		return nil, fmt.Errorf("ntfy notification target requires a topic url")
+	}
+
+	priority := target.Options[NtfyPriorityOption]
+	if priority != "" && !ntfyPriorities[priority] {
+		return nil, fmt.Errorf("invalid ntfy priority %q", priority)
+	}
+
+	return &NtfyNotifier{
+		topic:    ntfy.NewNtfyTopic(target.URL, target.Options[NtfyTokenOption]),
+		priority: priority,
+	}, nil
+}
+
+func (n *NtfyNotifier) Notify(ctx context.Context, notification Notification) error {
+	for i, item := range notification.Items {
+		if err := n.topic.Publish(ctx, n.createMessage(notification, item)); err != nil {
+			return partialDelivery(notification.Items[:i], fmt.Errorf("failed to send item %d: ntfy error: %w", item.ID, err))
+		}
+	}
+
+	return nil
+}
+
+func (n *NtfyNotifier) createMessage(notification Notification, item vinted.Item) ntfy.Message {
+	formatted := notification.format(item)
+
+	tag := "shopping_bags"
+	if notification.IsPriceDrop() {
+		tag = "chart_with_downwards_trend"
+	}
+	if notification.IsSold() {
+		tag = "white_check_mark"
+	}
+
+	lines := []string{fmt.Sprintf("🔍 %s", notification.Search.Name)}
+	for _, field := range formatted.Fields {
+		lines = append(lines, fmt.Sprintf("%s: %s", field.Name, field.Value))
+	}
+
+	return ntfy.Message{
+		Title:    truncateTitle(formatted.Title, 256),
+		Body:     strings.Join(lines, "\n"),
+		Priority: n.priority,
+		Tags:     []string{tag},
+		Click:    formatted.URL,
+		Attach:   formatted.ImageURL,
+	}
+}
@@ -0,0 +1,270 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/storage"
+	"vinted-watcher/internal/vinted"
+)
+
+const (
+	DefaultOutboxMaxAttempts     = 8
+	DefaultOutboxBaseBackoff     = 30 * time.Second
+	DefaultOutboxMaxBackoff      = 1 * time.Hour
+	DefaultOutboxBatchSize       = 100
+	DefaultOutboxDeliveryTimeout = 10 * time.Second
+)
+
+type OutboxConfig struct {
+	// MaxAttempts is the number of failed deliveries after which an entry is dead-lettered
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry, doubling with every further failure up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// BatchSize caps the number of entries picked up per delivery run
+	BatchSize       int
+	DeliveryTimeout time.Duration
+}
+
+// OutboxStore is the storage the outbox delivers from
+type OutboxStore interface {
+	GetSearchByID(ctx context.Context, id int) (*domain.SavedSearch, error)
+	GetDueOutboxEntries(ctx context.Context, now time.Time, limit int) ([]storage.OutboxEntry, error)
+	DeleteOutboxEntries(ctx context.Context, ids []int) error
+	RecordOutboxFailure(ctx context.Context, id int, lastError string, nextAttemptAt time.Time, dead bool) error
+}
+
+// Outbox delivers queued notifications, retrying failures with exponential backoff until they
+// succeed or are dead-lettered
+type Outbox struct {
+	store    OutboxStore
+	registry *Registry
+	config   OutboxConfig
+	// mu serialises deliveries so an entry is never sent by two runs at once
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+func NewOutbox(store OutboxStore, registry *Registry, config OutboxConfig) *Outbox {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultOutboxMaxAttempts
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = DefaultOutboxBaseBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultOutboxMaxBackoff
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultOutboxBatchSize
+	}
+	if config.DeliveryTimeout <= 0 {
+		config.DeliveryTimeout = DefaultOutboxDeliveryTimeout
+	}
+
+	return &Outbox{
+		store:    store,
+		registry: registry,
+		config:   config,
+		now:      time.Now,
+	}
+}
+
+// Run delivers due entries on every tick until the context is cancelled
+func (o *Outbox) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := o.DeliverDue(ctx); err != nil {
+				slog.Error("Error delivering notifications", "error", err)
+			}
+		case <-ctx.Done():
+			slog.Info("Stopping notification outbox...")
+			return
+		}
+	}
+}
+
+// DeliverDue sends every due entry, batching items queued for the same search and target into
+// a single notification
+func (o *Outbox) DeliverDue(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries, err := o.store.GetDueOutboxEntries(ctx, o.now(), o.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get due outbox entries: %w", err)
+	}
+
+	var errs []error
+	for _, group := range groupOutboxEntries(entries) {
+		if err := o.deliver(ctx, group); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (o *Outbox) deliver(ctx context.Context, entries []storage.OutboxEntry) error {
+	searchID, target := entries[0].SearchID, entries[0].Target
+
+	search, err := o.store.GetSearchByID(ctx, searchID)
+	if err != nil {
+		return fmt.Errorf("failed to get search %d: %w", searchID, err)
+	}
+
+	notification := Notification{
+		Items:       make([]vinted.Item, 0, len(entries)),
+		DeliveryIDs: make(map[int64]int, len(entries)),
+	}
+	ids := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+		notification.Items = append(notification.Items, entry.Item)
+		notification.DeliveryIDs[entry.Item.ID] = entry.ID
+
+		if entry.PreviousPrice != nil {
+			if notification.PreviousPrices == nil {
+				notification.PreviousPrices = make(map[int64]vinted.Price)
+			}
+			notification.PreviousPrices[entry.Item.ID] = *entry.PreviousPrice
+		}
+
+		if entry.DealScore != nil {
+			if notification.DealScores == nil {
+				notification.DealScores = make(map[int64]float64)
+			}
+			notification.DealScores[entry.Item.ID] = *entry.DealScore
+		}
+
+		if entry.TimeToSell != nil {
+			if notification.TimesToSell == nil {
+				notification.TimesToSell = make(map[int64]time.Duration)
+			}
+			notification.TimesToSell[entry.Item.ID] = *entry.TimeToSell
+		}
+	}
+
+	// The search was deleted after the items were queued
+	if search == nil {
+		return o.store.DeleteOutboxEntries(ctx, ids)
+	}
+
+	notification.Search = *search
+	deliverErr := o.notify(ctx, target, notification)
+	if deliverErr == nil {
+		return o.store.DeleteOutboxEntries(ctx, ids)
+	}
+
+	// Items delivered before the failure are done with, only the rest are retried
+	var partial *PartialDeliveryError
+	if errors.As(deliverErr, &partial) {
+		entries, err = o.deletePartialDelivery(ctx, entries, partial.Delivered)
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.Warn("Notification delivery failed", "search_id", searchID, "target_type", target.Type, "item_count", len(entries), "error", deliverErr)
+
+	for _, entry := range entries {
+		attempts := entry.Attempts + 1
+		dead := attempts >= o.config.MaxAttempts
+		nextAttemptAt := o.now().Add(o.backoff(attempts))
+
+		if err := o.store.RecordOutboxFailure(ctx, entry.ID, deliverErr.Error(), nextAttemptAt, dead); err != nil {
+			return fmt.Errorf("failed to record delivery failure for outbox entry %d: %w", entry.ID, err)
+		}
+
+		if dead {
+			slog.Error("Notification dead-lettered", "outbox_id", entry.ID, "search_id", searchID, "item_id", entry.Item.ID, "attempts", attempts)
+		}
+	}
+
+	return fmt.Errorf("search %d: %s notification failed: %w", searchID, target.Type, deliverErr)
+}
+
+// deletePartialDelivery deletes the entries for the delivered items and returns the rest
+func (o *Outbox) deletePartialDelivery(ctx context.Context, entries []storage.OutboxEntry, deliveredItemIDs []int64) ([]storage.OutboxEntry, error) {
+	delivered := make(map[int64]bool, len(deliveredItemIDs))
+	for _, id := range deliveredItemIDs {
+		delivered[id] = true
+	}
+
+	deliveredIDs := make([]int, 0, len(deliveredItemIDs))
+	remaining := make([]storage.OutboxEntry, 0, len(entries))
+	for _, entry := range entries {
+		if delivered[entry.Item.ID] {
+			deliveredIDs = append(deliveredIDs, entry.ID)
+		} else {
+			remaining = append(remaining, entry)
+		}
+	}
+
+	if err := o.store.DeleteOutboxEntries(ctx, deliveredIDs); err != nil {
+		return nil, fmt.Errorf("failed to delete delivered outbox entries: %w", err)
+	}
+	return remaining, nil
+}
+
+func (o *Outbox) notify(ctx context.Context, target domain.NotificationTarget, notification Notification) error {
+	n, err := o.registry.get(target)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.config.DeliveryTimeout)
+	defer cancel()
+
+	return n.Notify(ctx, notification)
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= o.config.MaxBackoff {
+			return o.config.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// groupOutboxEntries groups entries by search, target and whether they announce new items,
+// price drops or sales, preserving queue order
+func groupOutboxEntries(entries []storage.OutboxEntry) [][]storage.OutboxEntry {
+	type groupKey struct {
+		searchID  int
+		target    string
+		priceDrop bool
+		sold      bool
+	}
+
+	groups := make(map[groupKey][]storage.OutboxEntry)
+	order := make([]groupKey, 0)
+	for _, entry := range entries {
+		target, _ := json.Marshal(entry.Target)
+		key := groupKey{searchID: entry.SearchID, target: string(target), priceDrop: entry.PreviousPrice != nil, sold: entry.TimeToSell != nil}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], entry)
+	}
+
+	result := make([][]storage.OutboxEntry, 0, len(order))
+	for _, key := range order {
+		result = append(result, groups[key])
+	}
+	return result
+}
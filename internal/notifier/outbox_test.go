@@ -0,0 +1,186 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/storage"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Outbox_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	db, err := storage.NewDB(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	target := domain.NotificationTarget{Type: domain.NotificationTargetSlack, URL: server.URL}
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+	_, err = db.RecordNewItems(ctx, searchID, []domain.NotificationTarget{target}, []vinted.Item{testItem()}, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	outbox := NewOutbox(db, NewRegistry(nil, Dependencies{}), OutboxConfig{
+		MaxAttempts: 3,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  90 * time.Second,
+	})
+	outbox.now = func() time.Time { return now }
+
+	require.Error(t, outbox.DeliverDue(ctx))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Not retried before the backoff elapses
+	require.NoError(t, outbox.DeliverDue(ctx))
+	assert.Equal(t, int32(1), requests.Load())
+
+	due, err := db.GetDueOutboxEntries(ctx, now.Add(time.Minute), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+
+	now = now.Add(time.Minute)
+	require.Error(t, outbox.DeliverDue(ctx))
+	assert.Equal(t, int32(2), requests.Load())
+
+	// The second backoff doubles but is capped
+	due, err = db.GetDueOutboxEntries(ctx, now.Add(89*time.Second), 100)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	now = now.Add(90 * time.Second)
+	require.Error(t, outbox.DeliverDue(ctx))
+	assert.Equal(t, int32(3), requests.Load())
+
+	dead, err := db.GetDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 3, dead[0].Attempts)
+	assert.Contains(t, dead[0].LastError, "500")
+
+	// Dead letters are left alone until replayed
+	now = now.Add(time.Hour)
+	require.NoError(t, outbox.DeliverDue(ctx))
+	assert.Equal(t, int32(3), requests.Load())
+
+	status.Store(http.StatusOK)
+	require.NoError(t, db.ReplayDeadLetter(ctx, dead[0].ID))
+	require.NoError(t, outbox.DeliverDue(ctx))
+	assert.Equal(t, int32(4), requests.Load())
+
+	dead, err = db.GetDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+	due, err = db.GetDueOutboxEntries(ctx, now.Add(time.Hour), 100)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+func Test_Outbox_BatchesItemsPerSearchAndTarget(t *testing.T) {
+	db, err := storage.NewDB(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	target := domain.NotificationTarget{Type: domain.NotificationTargetSlack, URL: server.URL}
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	first, second := testItem(), testItem()
+	second.ID++
+	_, err = db.RecordNewItems(ctx, searchID, []domain.NotificationTarget{target}, []vinted.Item{first, second}, nil)
+	require.NoError(t, err)
+
+	outbox := NewOutbox(db, NewRegistry(nil, Dependencies{}), OutboxConfig{})
+	require.NoError(t, outbox.DeliverDue(ctx))
+	assert.Equal(t, int32(1), requests.Load())
+
+	due, err := db.GetDueOutboxEntries(ctx, time.Now().Add(time.Hour), 100)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+func Test_Outbox_RetriesOnlyUndeliveredItems(t *testing.T) {
+	db, err := storage.NewDB(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	var failing atomic.Bool
+	failing.Store(true)
+	deliveries := make(map[int64][]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		if payload.Item.ID == 3 && failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		deliveries[payload.Item.ID] = append(deliveries[payload.Item.ID], payload.DeliveryID)
+	}))
+	defer server.Close()
+
+	target := domain.NotificationTarget{Type: domain.NotificationTargetWebhook, URL: server.URL, Options: map[string]string{WebhookSecretOption: "s3cret"}}
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	items := []vinted.Item{testItem(), testItem(), testItem(), testItem()}
+	for i := range items {
+		items[i].ID = int64(i + 1)
+	}
+	_, err = db.RecordNewItems(ctx, searchID, []domain.NotificationTarget{target}, items, nil)
+	require.NoError(t, err)
+
+	queued, err := db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	require.Len(t, queued, 4)
+
+	now := time.Now()
+	outbox := NewOutbox(db, NewRegistry(nil, Dependencies{}), OutboxConfig{BaseBackoff: time.Minute})
+	outbox.now = func() time.Time { return now }
+
+	require.Error(t, outbox.DeliverDue(ctx))
+
+	// Only the failed item and those after it are left to retry
+	due, err := db.GetDueOutboxEntries(ctx, now.Add(time.Minute), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, int64(3), due[0].Item.ID)
+	assert.Equal(t, int64(4), due[1].Item.ID)
+	assert.Equal(t, 1, due[0].Attempts)
+
+	failing.Store(false)
+	now = now.Add(time.Minute)
+	require.NoError(t, outbox.DeliverDue(ctx))
+
+	expected := make(map[int64][]int)
+	for _, entry := range queued {
+		expected[entry.Item.ID] = []int{entry.ID}
+	}
+	assert.Equal(t, expected, deliveries, "each item should be delivered once with its outbox entry as the delivery ID")
+}
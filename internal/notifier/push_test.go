@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NtfyNotifier_PublishesItem(t *testing.T) {
+	var headers http.Header
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		raw, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		body = string(raw)
+	}))
+	defer server.Close()
+
+	n, err := New(domain.NotificationTarget{
+		Type: domain.NotificationTargetNtfy,
+		URL:  server.URL + "/vinted",
+		Options: map[string]string{
+			NtfyTokenOption:    "tk_123",
+			NtfyPriorityOption: "high",
+		},
+	}, Dependencies{})
+	require.NoError(t, err)
+
+	err = n.Notify(context.Background(), Notification{Search: domain.SavedSearch{Name: "barbour"}, Items: []vinted.Item{testItem()}})
+	require.NoError(t, err)
+
+	title, err := new(mime.WordDecoder).DecodeHeader(headers.Get("Title"))
+	require.NoError(t, err)
+	assert.Equal(t, "Barbour <Bedale> jacket", title)
+	assert.Equal(t, "high", headers.Get("Priority"))
+	assert.Equal(t, "https://www.vinted.co.uk/items/1", headers.Get("Click"))
+	assert.Equal(t, "https://images.vinted.net/1.jpg", headers.Get("Attach"))
+	assert.Equal(t, "Bearer tk_123", headers.Get("Authorization"))
+	assert.Equal(t, "🔍 barbour\n💰 Price: £40.0\n📏 Size: M\n🏷️ Brand: Barbour", body)
+}
+
+func Test_NtfyTargetRejectsUnknownPriority(t *testing.T) {
+	assert.Error(t, Validate([]domain.NotificationTarget{{
+		Type:    domain.NotificationTargetNtfy,
+		URL:     "https://ntfy.sh/vinted",
+		Options: map[string]string{NtfyPriorityOption: "loud"},
+	}}, Dependencies{}))
+}
+
+func Test_GotifyNotifier_PostsMarkdownMessage(t *testing.T) {
+	var appToken string
+	var message map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/message", r.URL.Path)
+		appToken = r.Header.Get("X-Gotify-Key")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+	}))
+	defer server.Close()
+
+	n, err := New(domain.NotificationTarget{
+		Type: domain.NotificationTargetGotify,
+		URL:  server.URL + "/",
+		Options: map[string]string{
+			GotifyTokenOption:    "AbCdEf",
+			GotifyPriorityOption: "8",
+		},
+	}, Dependencies{})
+	require.NoError(t, err)
+
+	err = n.Notify(context.Background(), Notification{Search: domain.SavedSearch{Name: "barbour"}, Items: []vinted.Item{testItem()}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "AbCdEf", appToken)
+	assert.Equal(t, "Barbour <Bedale> jacket", message["title"])
+	assert.Equal(t, float64(8), message["priority"])
+	assert.Contains(t, message["message"], "![Barbour <Bedale> jacket](https://images.vinted.net/1.jpg)")
+	assert.Contains(t, message["message"], "**💰 Price**: £40.0")
+	assert.Contains(t, message["message"], "[Open on Vinted](https://www.vinted.co.uk/items/1)")
+
+	extras := message["extras"].(map[string]any)
+	assert.Equal(t, "text/markdown", extras["client::display"].(map[string]any)["contentType"])
+	assert.Equal(t, "https://www.vinted.co.uk/items/1", extras["client::notification"].(map[string]any)["click"].(map[string]any)["url"])
+}
+
+func Test_GotifyTargetRequiresToken(t *testing.T) {
+	assert.Error(t, Validate([]domain.NotificationTarget{{Type: domain.NotificationTargetGotify, URL: "https://gotify.example"}}, Dependencies{}))
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"net/url"
+	"strings"
+	"vinted-watcher/internal/domain"
+)
+
+const redacted = "REDACTED"
+
+// secretOptions are the target options that grant access to a channel. NtfyTokenOption
+// and GotifyTokenOption share the same key.
+var secretOptions = map[string]bool{
+	TelegramBotTokenOption: true,
+	WebhookSecretOption:    true,
+	NtfyTokenOption:        true,
+}
+
+// secretURLTargets are the target types whose URL alone is enough to post to the channel.
+// Telegram and Gotify URLs only point at a server and are authorised by a token option.
+var secretURLTargets = map[string]bool{
+	domain.NotificationTargetDiscord: true,
+	domain.NotificationTargetSlack:   true,
+	domain.NotificationTargetWebhook: true,
+	domain.NotificationTargetNtfy:    true,
+}
+
+// Redact returns a copy of the target that is safe to show to API clients, with secret options
+// replaced and secret URLs cut down to their scheme and host
+func Redact(target domain.NotificationTarget) domain.NotificationTarget {
+	if target.URL != "" && secretURLTargets[target.Type] {
+		target.URL = redactURL(target.URL)
+	}
+
+	if target.Options != nil {
+		options := make(map[string]string, len(target.Options))
+		for key, value := range target.Options {
+			if secretOptions[key] && value != "" {
+				value = redacted
+			}
+			options[key] = value
+		}
+		target.Options = options
+	}
+
+	return target
+}
+
+// RedactTargets redacts every target, see Redact
+func RedactTargets(targets []domain.NotificationTarget) []domain.NotificationTarget {
+	if targets == nil {
+		return nil
+	}
+
+	result := make([]domain.NotificationTarget, len(targets))
+	for i, target := range targets {
+		result[i] = Redact(target)
+	}
+	return result
+}
+
+// RedactText removes the target's secrets from text such as a delivery error, which often
+// quotes the URL that was requested
+func RedactText(target domain.NotificationTarget, text string) string {
+	if target.URL != "" && secretURLTargets[target.Type] {
+		text = strings.ReplaceAll(text, target.URL, redactURL(target.URL))
+	}
+
+	for key, value := range target.Options {
+		if secretOptions[key] && value != "" {
+			text = strings.ReplaceAll(text, value, redacted)
+		}
+	}
+
+	return text
+}
+
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return redacted
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/" + redacted
+}
@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"testing"
+	"vinted-watcher/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Redact_HidesSecretURLsAndOptions(t *testing.T) {
+	targets := []domain.NotificationTarget{
+		{Type: domain.NotificationTargetDiscord, URL: "https://discord.com/api/webhooks/1/abc"},
+		{Type: domain.NotificationTargetTelegram, Options: map[string]string{TelegramBotTokenOption: "123:secret", TelegramChatIDOption: "42"}},
+		{Type: domain.NotificationTargetWebhook, URL: "https://example.com/hook?key=abc", Options: map[string]string{WebhookSecretOption: "s3cret"}},
+		{Type: domain.NotificationTargetGotify, URL: "https://gotify.example.com", Options: map[string]string{GotifyTokenOption: "tok", GotifyPriorityOption: "5"}},
+	}
+
+	expected := []domain.NotificationTarget{
+		{Type: domain.NotificationTargetDiscord, URL: "https://discord.com/REDACTED"},
+		{Type: domain.NotificationTargetTelegram, Options: map[string]string{TelegramBotTokenOption: "REDACTED", TelegramChatIDOption: "42"}},
+		{Type: domain.NotificationTargetWebhook, URL: "https://example.com/REDACTED", Options: map[string]string{WebhookSecretOption: "REDACTED"}},
+		{Type: domain.NotificationTargetGotify, URL: "https://gotify.example.com", Options: map[string]string{GotifyTokenOption: "REDACTED", GotifyPriorityOption: "5"}},
+	}
+
+	assert.Equal(t, expected, RedactTargets(targets))
+	assert.Equal(t, "123:secret", targets[1].Options[TelegramBotTokenOption], "the original target should be unchanged")
+}
+
+func Test_RedactText_RemovesSecretsFromErrors(t *testing.T) {
+	telegramTarget := domain.NotificationTarget{Type: domain.NotificationTargetTelegram, Options: map[string]string{TelegramBotTokenOption: "123:secret", TelegramChatIDOption: "42"}}
+	assert.Equal(t,
+		`Post "https://api.telegram.org/botREDACTED/sendPhoto": EOF`,
+		RedactText(telegramTarget, `Post "https://api.telegram.org/bot123:secret/sendPhoto": EOF`))
+
+	discordTarget := domain.NotificationTarget{Type: domain.NotificationTargetDiscord, URL: "https://discord.com/api/webhooks/1/abc"}
+	assert.Equal(t,
+		`Post "https://discord.com/REDACTED": EOF`,
+		RedactText(discordTarget, `Post "https://discord.com/api/webhooks/1/abc": EOF`))
+}
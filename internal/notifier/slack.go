@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"vinted-watcher/internal/slack"
+	"vinted-watcher/internal/vinted"
+)
+
+const maxSlackItemsPerMessage = 20 // Slack allows 50 blocks per message and each item uses two
+
+// SlackNotifier posts new items to a Slack incoming webhook as Block Kit messages
+type SlackNotifier struct {
+	webhook *slack.SlackWebhook
+}
+
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{webhook: slack.NewSlackWebhook(webhookURL)}
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, notification Notification) error {
+	if len(notification.Items) == 0 {
+		return nil // No items to notify about
+	}
+
+	batches := createItemBatches(notification.Items, maxSlackItemsPerMessage)
+	delivered := make([]vinted.Item, 0, len(notification.Items))
+
+	for i, batch := range batches {
+		message := createSlackMessage(notification, batch, i, len(batches))
+		if err := s.webhook.PostMessage(ctx, message); err != nil {
+			return partialDelivery(delivered, fmt.Errorf("failed to send batch %d: slack API error: %w", i+1, err))
+		}
+		delivered = append(delivered, batch...)
+	}
+
+	return nil
+}
+
+func createSlackMessage(notification Notification, items []vinted.Item, batchNum, totalBatches int) slack.WebhookMessage {
+	content := formatSlackMessageContent(notification, len(items), batchNum, totalBatches)
+
+	message := slack.WebhookMessage{
+		Text:   content,
+		Blocks: []slack.Block{slack.NewSectionBlock(content)},
+	}
+
+	for _, item := range items {
+		message.Blocks = append(message.Blocks, slack.NewDividerBlock(), createItemBlock(notification.format(item)))
+	}
+
+	return message
+}
+
+func formatSlackMessageContent(notification Notification, itemCount, batchNum, totalBatches int) string {
+	searchName := notification.Search.Name
+	if searchURL := notification.searchURL(); searchURL != "" {
+		searchName = fmt.Sprintf("<%s|%s>", searchURL, escapeSlackText(searchName))
+	}
+
+	if totalBatches == 1 {
+		return fmt.Sprintf("🔍 *%s*: %s", searchName, notification.summary(itemCount))
+	}
+
+	return fmt.Sprintf("🔍 *%s*: Batch %d/%d", searchName, batchNum+1, totalBatches)
+}
+
+func createItemBlock(formatted formattedItem) slack.Block {
+	title := escapeSlackText(truncateTitle(formatted.Title, 256))
+	if formatted.URL != "" {
+		title = fmt.Sprintf("<%s|%s>", formatted.URL, title)
+	}
+
+	block := slack.NewSectionBlock(fmt.Sprintf("*%s*", title))
+	for _, field := range formatted.Fields {
+		block.Fields = append(block.Fields, slack.NewMarkdownText(fmt.Sprintf("*%s*\n%s", field.Name, escapeSlackText(field.Value))))
+	}
+
+	// Add image if available
+	if formatted.ImageURL != "" {
+		block.Accessory = slack.NewImageAccessory(formatted.ImageURL, formatted.Title)
+	}
+
+	return block
+}
+
+// escapeSlackText escapes the control characters Slack's mrkdwn format reserves
+func escapeSlackText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
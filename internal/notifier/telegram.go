@@ -0,0 +1,124 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/telegram"
+	"vinted-watcher/internal/vinted"
+)
+
+const (
+	TelegramBotTokenOption = "bot_token"
+	TelegramChatIDOption   = "chat_id"
+)
+
+// TelegramNotifier sends each new item to a Telegram chat as a photo with a link button
+type TelegramNotifier struct {
+	bot    *telegram.TelegramBot
+	chatID string
+}
+
+func NewTelegramNotifier(bot *telegram.TelegramBot, chatID string) *TelegramNotifier {
+	return &TelegramNotifier{bot: bot, chatID: chatID}
+}
+
+func newTelegramNotifierFromTarget(target domain.NotificationTarget) (*TelegramNotifier, error) {
+	token := target.Options[TelegramBotTokenOption]
+	chatID := target.Options[TelegramChatIDOption]
+	if token == "" || chatID == "" {
+		return nil, fmt.Errorf("telegram notification target requires %q and %q options", TelegramBotTokenOption, TelegramChatIDOption)
+	}
+
+	bot := telegram.NewTelegramBot(token)
+	// The target URL optionally points at a self-hosted Bot API server
+	if target.URL != "" {
+		bot = telegram.NewTelegramBotWithClient(target.URL, token, &http.Client{Timeout: 10 * time.Second})
+	}
+
+	return NewTelegramNotifier(bot, chatID), nil
+}
+
+func (t *TelegramNotifier) Notify(ctx context.Context, notification Notification) error {
+	for i, item := range notification.Items {
+		if err := t.sendItem(ctx, notification, item); err != nil {
+			return partialDelivery(notification.Items[:i], fmt.Errorf("failed to send item %d: telegram API error: %w", item.ID, err))
+		}
+	}
+
+	return nil
+}
+
+func (t *TelegramNotifier) sendItem(ctx context.Context, notification Notification, item vinted.Item) error {
+	caption := createTelegramCaption(notification, item)
+	markup := createTelegramKeyboard(item)
+
+	sendMessage := func() error {
+		return t.bot.SendMessage(ctx, telegram.SendMessageRequest{
+			ChatID:      t.chatID,
+			Text:        caption,
+			ParseMode:   "HTML",
+			ReplyMarkup: markup,
+		})
+	}
+
+	// Fall back to a text message for items without a photo
+	if item.Photo.URL == "" {
+		return sendMessage()
+	}
+
+	err := t.bot.SendPhoto(ctx, telegram.SendPhotoRequest{
+		ChatID:      t.chatID,
+		Photo:       item.Photo.URL,
+		Caption:     caption,
+		ParseMode:   "HTML",
+		ReplyMarkup: markup,
+	})
+
+	// Telegram rejects photos it cannot fetch, so a broken image still gets a text message
+	var apiErr *telegram.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		slog.Warn("telegram rejected item photo, sending text instead", "item_id", item.ID, "error", err)
+		return sendMessage()
+	}
+
+	return err
+}
+
+func createTelegramCaption(notification Notification, item vinted.Item) string {
+	formatted := notification.format(item)
+
+	searchName := html.EscapeString(truncateTitle(notification.Search.Name, 256))
+	if searchURL := notification.searchURL(); searchURL != "" {
+		searchName = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(searchURL), searchName)
+	}
+
+	lines := []string{
+		fmt.Sprintf("🔍 %s", searchName),
+		fmt.Sprintf("<b>%s</b>", html.EscapeString(truncateTitle(formatted.Title, 256))),
+	}
+	for _, field := range formatted.Fields {
+		lines = append(lines, fmt.Sprintf("%s: %s", field.Name, html.EscapeString(field.Value)))
+	}
+
+	// Titles are truncated so the caption stays within the Bot API's 1024 character limit
+	return strings.Join(lines, "\n")
+}
+
+func createTelegramKeyboard(item vinted.Item) *telegram.InlineKeyboardMarkup {
+	if item.URL == "" {
+		return nil
+	}
+
+	return &telegram.InlineKeyboardMarkup{
+		InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: "Open on Vinted", URL: item.URL}},
+		},
+	}
+}
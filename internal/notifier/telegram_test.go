@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TelegramNotifier_SendsPhotoPerItem(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string][]map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mu.Lock()
+		requests[r.URL.Path] = append(requests[r.URL.Path], body)
+		mu.Unlock()
+
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer server.Close()
+
+	n, err := New(domain.NotificationTarget{
+		Type: domain.NotificationTargetTelegram,
+		URL:  server.URL,
+		Options: map[string]string{
+			TelegramBotTokenOption: "123:abc",
+			TelegramChatIDOption:   "42",
+		},
+	}, Dependencies{})
+	require.NoError(t, err)
+
+	withoutPhoto := testItem()
+	withoutPhoto.ID = 2
+	withoutPhoto.Photo = vinted.ItemPhoto{}
+
+	err = n.Notify(context.Background(), Notification{
+		Search: domain.SavedSearch{Name: "barbour"},
+		Items:  []vinted.Item{testItem(), withoutPhoto},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, requests["/bot123:abc/sendPhoto"], 1)
+	photo := requests["/bot123:abc/sendPhoto"][0]
+	assert.Equal(t, "42", photo["chat_id"])
+	assert.Equal(t, "https://images.vinted.net/1.jpg", photo["photo"])
+	assert.Equal(t, "🔍 barbour\n<b>Barbour &lt;Bedale&gt; jacket</b>\n💰 Price: £40.0\n📏 Size: M\n🏷️ Brand: Barbour", photo["caption"])
+
+	require.Len(t, requests["/bot123:abc/sendMessage"], 1)
+	assert.Contains(t, requests["/bot123:abc/sendMessage"][0]["text"], "Barbour &lt;Bedale&gt; jacket")
+}
+
+func Test_TelegramTargetRequiresTokenAndChatID(t *testing.T) {
+	_, err := New(domain.NotificationTarget{
+		Type:    domain.NotificationTargetTelegram,
+		Options: map[string]string{TelegramBotTokenOption: "123:abc"},
+	}, Dependencies{})
+	assert.Error(t, err)
+}
+
+func Test_TelegramNotifier_FallsBackToMessageWhenPhotoRejected(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/bot123:abc/sendPhoto" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer server.Close()
+
+	n, err := New(domain.NotificationTarget{
+		Type: domain.NotificationTargetTelegram,
+		URL:  server.URL,
+		Options: map[string]string{
+			TelegramBotTokenOption: "123:abc",
+			TelegramChatIDOption:   "42",
+		},
+	}, Dependencies{})
+	require.NoError(t, err)
+
+	err = n.Notify(context.Background(), Notification{
+		Search: domain.SavedSearch{Name: "barbour"},
+		Items:  []vinted.Item{testItem()},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/bot123:abc/sendPhoto", "/bot123:abc/sendMessage"}, paths)
+}
@@ -0,0 +1,160 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+	"vinted-watcher/internal/webhook"
+)
+
+const (
+	WebhookSecretOption = "secret"
+
+	// WebhookPayloadVersion is bumped whenever the payload changes in a backwards incompatible way
+	WebhookPayloadVersion = 1
+	WebhookEventNewItem   = "item.new"
+	// WebhookEventPriceDrop payloads carry the price the item was reduced from
+	WebhookEventPriceDrop = "item.price_drop"
+	// WebhookEventSold payloads carry how long the item was listed before it sold
+	WebhookEventSold = "item.sold"
+)
+
+// WebhookPayload is the stable JSON document POSTed to generic webhooks for each new item
+type WebhookPayload struct {
+	Version int    `json:"version"`
+	Event   string `json:"event"`
+	// DeliveryID identifies the notification and is repeated when a failed delivery is retried,
+	// so receivers can drop duplicates
+	DeliveryID int           `json:"delivery_id,omitempty"`
+	SentAt     time.Time     `json:"sent_at"`
+	Search     WebhookSearch `json:"search"`
+	Item       WebhookItem   `json:"item"`
+	// PreviousPrice is set for item.price_drop events
+	PreviousPrice *WebhookPrice `json:"previous_price,omitempty"`
+	// TimeToSellSeconds is set for item.sold events
+	TimeToSellSeconds *int64 `json:"time_to_sell_seconds,omitempty"`
+}
+
+type WebhookSearch struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	SearchText string `json:"search_text"`
+	// URL links to the search on the Vinted website
+	URL string `json:"url,omitempty"`
+}
+
+type WebhookItem struct {
+	ID         int64         `json:"id"`
+	Title      string        `json:"title"`
+	URL        string        `json:"url"`
+	Price      WebhookPrice  `json:"price"`
+	TotalPrice WebhookPrice  `json:"total_price"`
+	Brand      string        `json:"brand"`
+	Size       string        `json:"size"`
+	Status     string        `json:"status"`
+	PhotoURL   string        `json:"photo_url"`
+	Seller     WebhookSeller `json:"seller"`
+	UploadedAt *time.Time    `json:"uploaded_at,omitempty"`
+}
+
+type WebhookPrice struct {
+	Amount       string `json:"amount"`
+	CurrencyCode string `json:"currency_code"`
+}
+
+type WebhookSeller struct {
+	ID       int    `json:"id"`
+	Login    string `json:"login"`
+	Business bool   `json:"business"`
+}
+
+// WebhookNotifier POSTs one signed JSON payload per new item to an arbitrary HTTP endpoint
+type WebhookNotifier struct {
+	webhook *webhook.SignedWebhook
+	now     func() time.Time
+}
+
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		webhook: webhook.NewSignedWebhook(url, secret),
+		now:     time.Now,
+	}
+}
+
+func newWebhookNotifierFromTarget(target domain.NotificationTarget) (*WebhookNotifier, error) {
+	secret := target.Options[WebhookSecretOption]
+	if target.URL == "" || secret == "" {
+		return nil, fmt.Errorf("webhook notification target requires a url and a %q option", WebhookSecretOption)
+	}
+	return NewWebhookNotifier(target.URL, secret), nil
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	for i, item := range notification.Items {
+		payload := w.createPayload(item, notification.Search)
+		payload.DeliveryID = notification.DeliveryIDs[item.ID]
+		if previous, ok := notification.PreviousPrices[item.ID]; ok {
+			payload.Event = WebhookEventPriceDrop
+			payload.PreviousPrice = &WebhookPrice{Amount: previous.Amount, CurrencyCode: previous.CurrencyCode}
+		}
+		if timeToSell, ok := notification.TimesToSell[item.ID]; ok {
+			seconds := int64(timeToSell.Seconds())
+			payload.Event = WebhookEventSold
+			payload.TimeToSellSeconds = &seconds
+		}
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload for item %d: %w", item.ID, err)
+		}
+
+		if err := w.webhook.Post(ctx, payload.Event, body); err != nil {
+			return partialDelivery(notification.Items[:i], fmt.Errorf("failed to send item %d: %w", item.ID, err))
+		}
+	}
+
+	return nil
+}
+
+func (w *WebhookNotifier) createPayload(item vinted.Item, search domain.SavedSearch) WebhookPayload {
+	payload := WebhookPayload{
+		Version: WebhookPayloadVersion,
+		Event:   WebhookEventNewItem,
+		SentAt:  w.now().UTC(),
+		Search: WebhookSearch{
+			ID:   search.ID,
+			Name: search.Name,
+		},
+		Item: WebhookItem{
+			ID:         item.ID,
+			Title:      item.Title,
+			URL:        item.URL,
+			Price:      WebhookPrice{Amount: item.Price.Amount, CurrencyCode: item.Price.CurrencyCode},
+			TotalPrice: WebhookPrice{Amount: item.TotalItemPrice.Amount, CurrencyCode: item.TotalItemPrice.CurrencyCode},
+			Brand:      item.BrandTitle,
+			Size:       item.SizeTitle,
+			Status:     item.Status,
+			PhotoURL:   item.Photo.URL,
+			Seller: WebhookSeller{
+				ID:       item.User.ID,
+				Login:    item.User.Login,
+				Business: item.User.Business,
+			},
+		},
+	}
+
+	if search.SearchParams != nil {
+		payload.Search.SearchText = search.SearchParams.SearchText
+		payload.Search.URL, _ = search.SearchParams.ToWebURL()
+	}
+
+	if timestamp := item.Photo.HighResolution.Timestamp; timestamp != 0 {
+		uploadedAt := time.Unix(int64(timestamp), 0).UTC()
+		payload.Item.UploadedAt = &uploadedAt
+	}
+
+	return payload
+}
@@ -0,0 +1,61 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+	"vinted-watcher/internal/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WebhookNotifier_PostsSignedPayloadPerItem(t *testing.T) {
+	payloads := make([]WebhookPayload, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.True(t, webhook.Verify([]byte("s3cret"), r.Header.Get(webhook.TimestampHeader), body, r.Header.Get(webhook.SignatureHeader)))
+
+		var payload WebhookPayload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	n, err := New(domain.NotificationTarget{
+		Type:    domain.NotificationTargetWebhook,
+		URL:     server.URL,
+		Options: map[string]string{WebhookSecretOption: "s3cret"},
+	}, Dependencies{})
+	require.NoError(t, err)
+
+	item := testItem()
+	item.User = vinted.User{ID: 7, Login: "reseller", Business: true}
+	item.TotalItemPrice = vinted.TotalItemPrice{Amount: "42.70", CurrencyCode: "GBP"}
+	item.Photo.HighResolution.Timestamp = 1756713600
+
+	search := domain.SavedSearch{ID: 3, Name: "barbour", SearchParams: &domain.SearchParams{SearchText: "barbour jacket"}}
+	require.NoError(t, n.Notify(context.Background(), Notification{Search: search, Items: []vinted.Item{item, item}}))
+
+	require.Len(t, payloads, 2)
+	payload := payloads[0]
+	assert.Equal(t, WebhookPayloadVersion, payload.Version)
+	assert.Equal(t, WebhookEventNewItem, payload.Event)
+	assert.Equal(t, WebhookSearch{ID: 3, Name: "barbour", SearchText: "barbour jacket", URL: "https://www.vinted.co.uk/catalog?order=newest_first&search_text=barbour+jacket"}, payload.Search)
+	assert.Equal(t, int64(1), payload.Item.ID)
+	assert.Equal(t, WebhookPrice{Amount: "40.0", CurrencyCode: "GBP"}, payload.Item.Price)
+	assert.Equal(t, WebhookPrice{Amount: "42.70", CurrencyCode: "GBP"}, payload.Item.TotalPrice)
+	assert.Equal(t, WebhookSeller{ID: 7, Login: "reseller", Business: true}, payload.Item.Seller)
+	require.NotNil(t, payload.Item.UploadedAt)
+	assert.Equal(t, int64(1756713600), payload.Item.UploadedAt.Unix())
+}
+
+func Test_WebhookTargetRequiresSecret(t *testing.T) {
+	assert.Error(t, Validate([]domain.NotificationTarget{{Type: domain.NotificationTargetWebhook, URL: "https://example.com/hook"}}, Dependencies{}))
+}
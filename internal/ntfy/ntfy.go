@@ -0,0 +1,105 @@
+package ntfy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type NtfyTopic struct {
+	topicURL string
+	token    string
+	client   *http.Client
+}
+
+// Message is a push notification published to an ntfy topic
+type Message struct {
+	Title    string
+	Body     string
+	Priority string
+	Tags     []string
+	// Click is opened when the notification is tapped
+	Click string
+	// Attach is the URL of a file, such as an image, shown with the notification
+	Attach string
+}
+
+// NewNtfyTopic creates a new ntfy publisher for a topic URL such as https://ntfy.sh/my-topic.
+// Token is an optional access token for protected topics.
+func NewNtfyTopic(topicURL, token string) *NtfyTopic {
+	return NewNtfyTopicWithClient(topicURL, token, &http.Client{
+		Timeout: 10 * time.Second,
+	})
+}
+
+// NewNtfyTopicWithClient creates a new ntfy publisher with a custom HTTP client
+func NewNtfyTopicWithClient(topicURL, token string, client *http.Client) *NtfyTopic {
+	return &NtfyTopic{
+		topicURL: topicURL,
+		token:    token,
+		client:   client,
+	}
+}
+
+func (n NtfyTopic) Publish(ctx context.Context, message Message) error {
+	if message.Body == "" {
+		return fmt.Errorf("message body cannot be empty")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.topicURL, strings.NewReader(message.Body))
+	if err != nil {
+		slog.Error("failed to create HTTP request", "error", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	setHeaderIfPresent(req, "Title", message.Title)
+	setHeaderIfPresent(req, "Priority", message.Priority)
+	setHeaderIfPresent(req, "Tags", strings.Join(message.Tags, ","))
+	setHeaderIfPresent(req, "Click", message.Click)
+	setHeaderIfPresent(req, "Attach", message.Attach)
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		slog.Error("error publishing to ntfy", "error", err)
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		slog.Info("message published successfully to ntfy", "status_code", resp.StatusCode)
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	slog.Error("failed to publish message to ntfy",
+		"status_code", resp.StatusCode,
+		"response_body", string(respBody))
+
+	return fmt.Errorf("failed to publish message (status: %d): %s", resp.StatusCode, string(respBody))
+}
+
+// setHeaderIfPresent sets a header, encoding non-ASCII values as RFC 2047 since ntfy reads
+// titles and tags from headers
+func setHeaderIfPresent(req *http.Request, key, value string) {
+	if value == "" {
+		return
+	}
+	req.Header.Set(key, encodeHeaderValue(value))
+}
+
+func encodeHeaderValue(value string) string {
+	for _, r := range value {
+		if r > 127 {
+			return mime.BEncoding.Encode("utf-8", value)
+		}
+	}
+	return value
+}
@@ -0,0 +1,91 @@
+package ntfy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Publish_SetsHeadersAndToken(t *testing.T) {
+	var gotRequest *http.Request
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		gotRequest, gotBody = r, string(body)
+	}))
+	defer server.Close()
+
+	topic := NewNtfyTopicWithClient(server.URL+"/vinted", "tk_secret", server.Client())
+	err := topic.Publish(context.Background(), Message{
+		Title:    "Barbour jacket",
+		Body:     "£45.00 - Size M",
+		Priority: "high",
+		Tags:     []string{"shopping", "new"},
+		Click:    "https://www.vinted.co.uk/items/1",
+		Attach:   "https://images.vinted.net/1.jpg",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, gotRequest.Method)
+	assert.Equal(t, "/vinted", gotRequest.URL.Path)
+	assert.Equal(t, "£45.00 - Size M", gotBody)
+	assert.Equal(t, "Bearer tk_secret", gotRequest.Header.Get("Authorization"))
+	assert.Equal(t, "Barbour jacket", gotRequest.Header.Get("Title"))
+	assert.Equal(t, "high", gotRequest.Header.Get("Priority"))
+	assert.Equal(t, "shopping,new", gotRequest.Header.Get("Tags"))
+	assert.Equal(t, "https://www.vinted.co.uk/items/1", gotRequest.Header.Get("Click"))
+	assert.Equal(t, "https://images.vinted.net/1.jpg", gotRequest.Header.Get("Attach"))
+}
+
+func Test_Publish_OmitsEmptyHeaders(t *testing.T) {
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+	}))
+	defer server.Close()
+
+	topic := NewNtfyTopicWithClient(server.URL+"/vinted", "", server.Client())
+	require.NoError(t, topic.Publish(context.Background(), Message{Body: "hello"}))
+
+	assert.Empty(t, gotHeader.Get("Authorization"))
+	assert.Empty(t, gotHeader.Get("Priority"))
+	assert.Empty(t, gotHeader.Get("Tags"))
+}
+
+func Test_Publish_EncodesNonASCIITitle(t *testing.T) {
+	var gotTitle string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTitle = r.Header.Get("Title")
+	}))
+	defer server.Close()
+
+	topic := NewNtfyTopicWithClient(server.URL+"/vinted", "", server.Client())
+	require.NoError(t, topic.Publish(context.Background(), Message{Title: "Veste Été", Body: "hello"}))
+
+	assert.Equal(t, "=?utf-8?b?VmVzdGUgw4l0w6k=?=", gotTitle)
+}
+
+func Test_Publish_ReturnsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"code":40301,"error":"forbidden"}`))
+	}))
+	defer server.Close()
+
+	topic := NewNtfyTopicWithClient(server.URL+"/vinted", "wrong", server.Client())
+	err := topic.Publish(context.Background(), Message{Body: "hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+	assert.Contains(t, err.Error(), "forbidden")
+}
+
+func Test_Publish_RequiresBody(t *testing.T) {
+	topic := NewNtfyTopic("https://ntfy.example/vinted", "")
+	assert.Error(t, topic.Publish(context.Background(), Message{Title: "empty"}))
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/scraper"
+)
+
+const DefaultTickInterval = 30 * time.Second
+
+// SearchScraper scrapes a set of saved searches
+type SearchScraper interface {
+	ScrapeSearches(ctx context.Context, searches []domain.SavedSearch) *scraper.ScraperResult
+}
+
+// SearchLister lists all saved searches
+type SearchLister interface {
+	GetAllSearches(ctx context.Context) ([]*domain.SavedSearch, error)
+}
+
+// Scheduler periodically scrapes the searches whose interval has elapsed since they were last checked
+type Scheduler struct {
+	scraper      SearchScraper
+	searches     SearchLister
+	tickInterval time.Duration
+	now          func() time.Time
+}
+
+func NewScheduler(scraper SearchScraper, searches SearchLister, tickInterval time.Duration) *Scheduler {
+	if tickInterval <= 0 {
+		tickInterval = DefaultTickInterval
+	}
+
+	return &Scheduler{
+		scraper:      scraper,
+		searches:     searches,
+		tickInterval: tickInterval,
+		now:          time.Now,
+	}
+}
+
+// Run checks for due searches immediately and then on every tick until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.tickInterval)
+	defer ticker.Stop()
+
+	s.safeTick(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			s.safeTick(ctx)
+		case <-ctx.Done():
+			slog.Info("Stopping scheduled scrape...")
+			return
+		}
+	}
+}
+
+// Tick scrapes every search that is currently due
+func (s *Scheduler) Tick(ctx context.Context) (*scraper.ScraperResult, error) {
+	dueSearches, err := s.dueSearches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dueSearches) == 0 {
+		slog.Debug("No searches due")
+		return &scraper.ScraperResult{}, nil
+	}
+
+	return s.scraper.ScrapeSearches(ctx, dueSearches), nil
+}
+
+func (s *Scheduler) dueSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	searches, err := s.searches.GetAllSearches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get searches: %w", err)
+	}
+
+	now := s.now()
+	due := make([]domain.SavedSearch, 0, len(searches))
+	for _, search := range searches {
+		if search.IsDue(now) {
+			due = append(due, *search)
+		}
+	}
+
+	return due, nil
+}
+
+func (s *Scheduler) safeTick(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Panic occurred during scraping:", "error", r)
+		}
+	}()
+
+	result, err := s.Tick(ctx)
+	if err != nil {
+		slog.Error("Error scraping:", "error", err)
+		return
+	}
+	slog.Debug("Scrape stats", "new_item_count", len(result.NewItems), "processed_searches_count", result.ProcessedSearches)
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/scraper"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeScraper struct {
+	mu    sync.Mutex
+	calls [][]domain.SavedSearch
+}
+
+func (f *fakeScraper) ScrapeSearches(ctx context.Context, searches []domain.SavedSearch) *scraper.ScraperResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, searches)
+	return &scraper.ScraperResult{ProcessedSearches: len(searches)}
+}
+
+func (f *fakeScraper) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+type fakeLister struct {
+	searches []*domain.SavedSearch
+	err      error
+}
+
+func (f *fakeLister) GetAllSearches(ctx context.Context) ([]*domain.SavedSearch, error) {
+	return f.searches, f.err
+}
+
+func Test_Tick_ScrapesOnlyDueSearches(t *testing.T) {
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	lister := &fakeLister{
+		searches: []*domain.SavedSearch{
+			{ID: 1, Active: true, Interval: 2 * time.Minute, LastChecked: now.Add(-3 * time.Minute)},
+			{ID: 2, Active: true, Interval: 6 * time.Hour, LastChecked: now.Add(-1 * time.Hour)},
+			{ID: 3, Active: true, Interval: time.Hour},
+			{ID: 4, Active: false, Interval: time.Minute},
+			{ID: 5, Active: true, Interval: time.Hour, LastChecked: now.Add(-time.Hour)},
+		},
+	}
+	fake := &fakeScraper{}
+
+	s := NewScheduler(fake, lister, time.Minute)
+	s.now = func() time.Time { return now }
+
+	result, err := s.Tick(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.ProcessedSearches)
+
+	require.Len(t, fake.calls, 1)
+	ids := make([]int, 0)
+	for _, search := range fake.calls[0] {
+		ids = append(ids, search.ID)
+	}
+	assert.Equal(t, []int{1, 3, 5}, ids)
+}
+
+func Test_Tick_SkipsScraperWhenNothingDue(t *testing.T) {
+	now := time.Now()
+	lister := &fakeLister{
+		searches: []*domain.SavedSearch{
+			{ID: 1, Active: true, Interval: time.Hour, LastChecked: now},
+		},
+	}
+	fake := &fakeScraper{}
+
+	s := NewScheduler(fake, lister, time.Minute)
+	s.now = func() time.Time { return now }
+
+	result, err := s.Tick(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ProcessedSearches)
+	assert.Empty(t, fake.calls)
+}
+
+func Test_Tick_ReturnsStorageError(t *testing.T) {
+	s := NewScheduler(&fakeScraper{}, &fakeLister{err: errors.New("db closed")}, time.Minute)
+
+	_, err := s.Tick(context.Background())
+	assert.Error(t, err)
+}
+
+func Test_Run_TicksOnStartupAndStopsOnCancel(t *testing.T) {
+	lister := &fakeLister{
+		searches: []*domain.SavedSearch{
+			{ID: 1, Active: true, Interval: time.Hour},
+		},
+	}
+	fake := &fakeScraper{}
+	s := NewScheduler(fake, lister, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return fake.callCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after context cancellation")
+	}
+}
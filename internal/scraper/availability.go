@@ -0,0 +1,160 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/storage"
+	"vinted-watcher/internal/vinted"
+)
+
+const (
+	DefaultAvailabilityRecheckAfter = 6 * time.Hour
+	DefaultAvailabilityBatchSize    = 50
+)
+
+// AvailabilityConfig configures how notified items are re-checked to detect when they sell or
+// are removed
+type AvailabilityConfig struct {
+	// RecheckAfter is how long an item that is still listed waits before it is checked again
+	RecheckAfter time.Duration
+	// BatchSize caps the number of items re-checked per run
+	BatchSize int
+	// NotifySold sends an "item you watched sold" notification to the item's searches
+	NotifySold bool
+}
+
+type AvailabilityResult struct {
+	Checked int
+	Sold    int
+	Removed int
+}
+
+// RunAvailabilityChecks re-checks notified items on every tick until the context is cancelled
+func (s *Scraper) RunAvailabilityChecks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			result, err := s.CheckAvailability(ctx)
+			if err != nil {
+				slog.Error("Error checking item availability", "error", err)
+			}
+			slog.Info("Item availability checked", "checked", result.Checked, "sold", result.Sold, "removed", result.Removed)
+		case <-ctx.Done():
+			slog.Info("Stopping item availability checks...")
+			return
+		}
+	}
+}
+
+// CheckAvailability fetches the details of notified items that are due a re-check and records
+// those that have sold or been removed. Items that can't be fetched are retried on the next run.
+func (s *Scraper) CheckAvailability(ctx context.Context) (*AvailabilityResult, error) {
+	config := s.config.Availability
+	items, err := s.db.GetItemsToRecheck(ctx, time.Now().Add(-config.RecheckAfter), config.BatchSize)
+	if err != nil {
+		return &AvailabilityResult{}, fmt.Errorf("failed to get items to re-check: %w", err)
+	}
+
+	result := &AvailabilityResult{}
+	var errs []error
+	for _, record := range items {
+		if ctx.Err() != nil {
+			break
+		}
+
+		availability, item, err := s.getItemAvailability(ctx, record)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %w", record.ID, err))
+			continue
+		}
+
+		switch availability {
+		case vinted.ItemSold:
+			err = s.recordItemSold(ctx, item)
+			result.Sold++
+		case vinted.ItemRemoved:
+			err = s.db.MarkItemRemoved(ctx, record.ID)
+			result.Removed++
+		default:
+			err = s.db.MarkItemAvailable(ctx, record.ID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %w", record.ID, err))
+			continue
+		}
+
+		result.Checked++
+	}
+
+	if result.Sold > 0 && config.NotifySold {
+		if err := s.outbox.DeliverDue(ctx); err != nil {
+			slog.Warn("Error delivering notifications, will retry", "err", err.Error())
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// getItemAvailability fetches the item's current listing from the site it was listed on. Items
+// Vinted no longer has are treated as removed.
+func (s *Scraper) getItemAvailability(ctx context.Context, record storage.ItemRecord) (string, vinted.Item, error) {
+	details, err := s.vintedClient.GetItemDetails(ctx, itemSiteURL(record.URL), record.ID)
+	if errors.Is(err, vinted.ErrItemNotFound) {
+		return vinted.ItemRemoved, vinted.Item{ID: record.ID}, nil
+	}
+	if err != nil {
+		return "", vinted.Item{}, fmt.Errorf("failed to get item details: %w", err)
+	}
+
+	item := details.Item
+	item.ID = record.ID
+	return details.Availability(), item, nil
+}
+
+// itemSiteURL returns the root URL of the Vinted site an item's listing is on, falling back to
+// the default site when its URL is unknown
+func itemSiteURL(itemURL string) string {
+	parsed, err := url.Parse(itemURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return (&domain.SearchParams{}).BaseURL()
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// recordItemSold marks the item as sold, queueing a notification to the searches it was
+// notified to when sold notifications are enabled
+func (s *Scraper) recordItemSold(ctx context.Context, item vinted.Item) error {
+	targets := make(map[int][]domain.NotificationTarget)
+	if s.config.Availability.NotifySold {
+		searchIDs, err := s.db.GetItemSearchIDs(ctx, item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get searches for item: %w", err)
+		}
+
+		for _, searchID := range searchIDs {
+			search, err := s.db.GetSearchByID(ctx, searchID)
+			if err != nil {
+				return fmt.Errorf("failed to get search %d: %w", searchID, err)
+			}
+			if search != nil {
+				targets[searchID] = s.notifiers.TargetsFor(*search)
+			}
+		}
+	}
+
+	timeToSell, err := s.db.MarkItemSold(ctx, item, targets)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Watched item sold", "item_id", item.ID, "time_to_sell", timeToSell)
+	return nil
+}
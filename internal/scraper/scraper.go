@@ -4,28 +4,46 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
-	"vinted-watcher/internal/discord"
 	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/market"
+	"vinted-watcher/internal/notifier"
 	"vinted-watcher/internal/storage"
 	"vinted-watcher/internal/vinted"
 )
 
-const (
-	maxEmbedsPerMessage = 10 // Discord limit
-	notificationTimeout = 10 * time.Second
-)
+const DefaultConcurrency = 4
+const DefaultMaxPages = 5
 
 type ScraperConfig struct {
-	LookbackPeriod                time.Duration
-	DiscordNotificationWebhookURL string
+	LookbackPeriod time.Duration
+	// DefaultNotificationTargets receive new items for searches without their own targets
+	DefaultNotificationTargets []domain.NotificationTarget
+	// Notifiers holds the services that stateful notifiers, such as email digests, depend on
+	Notifiers notifier.Dependencies
+	// Concurrency is the maximum number of searches processed in parallel
+	Concurrency int
+	// MaxPages caps how many pages of results are fetched per search when looking for the
+	// newest item already seen
+	MaxPages int
+	// Outbox configures how queued notifications are retried
+	Outbox notifier.OutboxConfig
+	// ExcludeBusinessSellers drops items from business sellers for every search
+	ExcludeBusinessSellers bool
+	// Availability configures re-checking notified items for sales and removals
+	Availability AvailabilityConfig
+	// EnrichNewItems fetches the details of each new item before it is notified, adding its
+	// description, colours, measurements, seller rating and photo gallery
+	EnrichNewItems bool
 }
 
 type Scraper struct {
 	vintedClient vinted.VintedClient
 	db           storage.SearchStorage
 	config       ScraperConfig
-	discord      *discord.DiscordWebhook
+	notifiers    *notifier.Registry
+	outbox       *notifier.Outbox
 }
 
 type ScraperResult struct {
@@ -35,52 +53,129 @@ type ScraperResult struct {
 }
 
 func NewScraper(vintedClient vinted.VintedClient, db storage.SearchStorage, config ScraperConfig) *Scraper {
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.MaxPages <= 0 {
+		config.MaxPages = DefaultMaxPages
+	}
+	if config.Availability.RecheckAfter <= 0 {
+		config.Availability.RecheckAfter = DefaultAvailabilityRecheckAfter
+	}
+	if config.Availability.BatchSize <= 0 {
+		config.Availability.BatchSize = DefaultAvailabilityBatchSize
+	}
+
+	notifiers := notifier.NewRegistry(config.DefaultNotificationTargets, config.Notifiers)
+
 	s := &Scraper{
 		vintedClient: vintedClient,
 		db:           db,
 		config:       config,
-	}
-
-	if config.DiscordNotificationWebhookURL != "" {
-		s.discord = discord.NewDiscordWebhook(config.DiscordNotificationWebhookURL)
+		notifiers:    notifiers,
+		outbox:       notifier.NewOutbox(db, notifiers, config.Outbox),
 	}
 
 	return s
 }
 
-func (s *Scraper) Scrape() (*ScraperResult, error) {
+// Outbox returns the outbox that delivers notifications for new items
+func (s *Scraper) Outbox() *notifier.Outbox {
+	return s.outbox
+}
+
+// ValidateNotificationTargets checks that the targets can be notified with the scraper's
+// notifier dependencies, such as the mailer for email digests
+func (s *Scraper) ValidateNotificationTargets(targets []domain.NotificationTarget) error {
+	return s.notifiers.Validate(targets)
+}
+
+// Scrape processes every active search regardless of when it was last checked
+func (s *Scraper) Scrape(ctx context.Context) (*ScraperResult, error) {
+	activeSearches, err := s.getActiveSearches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get searches: %w", err)
+	}
 
-	slog.Info("Scraping...")
+	return s.ScrapeSearches(ctx, activeSearches), nil
+}
+
+// ScrapeSearches processes the given searches using a bounded pool of workers and records
+// when each was last checked. Searches not yet started when ctx is cancelled are skipped.
+func (s *Scraper) ScrapeSearches(ctx context.Context, searches []domain.SavedSearch) *ScraperResult {
+	slog.Info("Scraping...", "search_count", len(searches), "concurrency", s.config.Concurrency)
 	result := &ScraperResult{
 		NewItems: make([]vinted.Item, 0),
 		Errors:   make([]error, 0),
 	}
 
-	activeSearches, err := s.getActiveSearches()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get searches: %w", err)
+	jobs := make(chan domain.SavedSearch)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < min(s.config.Concurrency, len(searches)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for search := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+
+				newItems, err := s.scrapeSearch(ctx, search)
+
+				mu.Lock()
+				result.addSearchResult(search, newItems, err)
+				mu.Unlock()
+			}
+		}()
 	}
 
-	for _, search := range activeSearches {
-		newItems, err := s.processSearch(search)
-		if err != nil {
-			slog.Error("Error processing search", "search_id", search.ID, "err", err.Error())
-			result.Errors = append(result.Errors, fmt.Errorf("search %d: %w", search.ID, err))
-			continue
+feed:
+	for _, search := range searches {
+		select {
+		case jobs <- search:
+		case <-ctx.Done():
+			slog.Info("Scrape cancelled", "err", ctx.Err())
+			break feed
 		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	slog.Info("Scraping complete")
+	return result
+}
 
-		result.NewItems = append(result.NewItems, newItems...)
-		result.ProcessedSearches++
+func (s *Scraper) scrapeSearch(ctx context.Context, search domain.SavedSearch) ([]vinted.Item, error) {
+	newItems, err := s.processSearch(ctx, search)
 
-		slog.Debug("Completed search", "search_id", search.ID, "new_items_count", len(newItems))
+	if ctx.Err() != nil {
+		return newItems, err
 	}
 
-	slog.Info("Scraping complete")
-	return result, nil
+	if updateErr := s.db.UpdateLastChecked(ctx, search.ID); updateErr != nil {
+		slog.Error("Error updating last checked time", "search_id", search.ID, "err", updateErr.Error())
+	}
+
+	return newItems, err
+}
+
+func (r *ScraperResult) addSearchResult(search domain.SavedSearch, newItems []vinted.Item, err error) {
+	if err != nil {
+		slog.Error("Error processing search", "search_id", search.ID, "err", err.Error())
+		r.Errors = append(r.Errors, fmt.Errorf("search %d: %w", search.ID, err))
+		return
+	}
+
+	r.NewItems = append(r.NewItems, newItems...)
+	r.ProcessedSearches++
+
+	slog.Debug("Completed search", "search_id", search.ID, "new_items_count", len(newItems))
 }
 
-func (s *Scraper) getActiveSearches() ([]domain.SavedSearch, error) {
-	searches, err := s.db.GetAllSearches()
+func (s *Scraper) getActiveSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	searches, err := s.db.GetAllSearches(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get searches: %w", err)
 	}
@@ -95,68 +190,148 @@ func (s *Scraper) getActiveSearches() ([]domain.SavedSearch, error) {
 	return activeSearches, nil
 }
 
-func (s *Scraper) processSearch(search domain.SavedSearch) ([]vinted.Item, error) {
-	items, err := s.getItemsForSearch(search)
+func (s *Scraper) processSearch(ctx context.Context, search domain.SavedSearch) ([]vinted.Item, error) {
+	items, err := s.getItemsForSearch(ctx, search)
 
 	slog.Info("Items found", "count", len(items))
 	if err != nil {
 		return nil, err
 	}
 
-	recentItems := s.filterItemsByLookback(items)
+	// History is kept for every item returned, not only those that match the search's filters
+	if err := s.db.RecordObservations(ctx, search.ID, items); err != nil {
+		slog.Error("Error recording item observations", "search_id", search.ID, "err", err.Error())
+	}
+
+	matchingItems, err := s.filterItemsForSearch(ctx, search, items)
+	if err != nil {
+		return nil, err
+	}
+
+	recentItems := s.filterItemsByLookback(matchingItems)
 
 	slog.Info("Items remaining after lookback filter", "count", len(recentItems))
 
-	newItems := make([]vinted.Item, 0)
+	// Scoring and enrichment are only worth their extra queries for items that will be notified
+	unseenItems, err := s.filterUnseenItems(ctx, search, recentItems)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, item := range recentItems {
-		isNew, err := s.processItem(search, item)
-		if err != nil {
-			return nil, fmt.Errorf("failed to process item %d: %w", item.ID, err)
-		}
+	slog.Info("Items not seen before", "count", len(unseenItems))
 
-		if isNew {
-			newItems = append(newItems, item)
+	dealScores, err := s.scoreDeals(ctx, unseenItems)
+	if err != nil {
+		return nil, err
+	}
+
+	if search.Filters.MaxDealPercentile > 0 {
+		var rejectedItems []vinted.Item
+		unseenItems, rejectedItems = filterItemsByDealScore(unseenItems, dealScores, search.Filters.MaxDealPercentile)
+		slog.Info("Items remaining after deal filter", "count", len(unseenItems))
+
+		// Rejected items are marked as seen without notifying, so a later change in the
+		// comparable listings can't turn them into a deal without a change in price
+		if _, err := s.db.RecordNewItems(ctx, search.ID, nil, rejectedItems, nil); err != nil {
+			return nil, fmt.Errorf("failed to record items rejected by the deal filter: %w", err)
 		}
+	}
+
+	if s.config.EnrichNewItems {
+		unseenItems = s.enrichItems(ctx, search, unseenItems)
+	}
+
+	targets := s.notifiers.TargetsFor(search)
 
-		slog.Debug("Processed item", "item_id", item.ID, "search_id", search.ID)
+	// Items are queued for notification in the same transaction that marks them as seen, so
+	// a failed delivery is retried by the outbox rather than lost
+	newItems, err := s.db.RecordNewItems(ctx, search.ID, targets, unseenItems, dealScores)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record new items: %w", err)
 	}
 
-	if s.discord != nil && len(newItems) > 0 {
-		slog.Info("posting discord notification for search", "search_id", search.ID)
-		err := s.postDiscordNotification(newItems, search)
+	// Price drops are checked across every matching item, including those outside the lookback
+	// period. Paging stops at the first observed item, so older listings further down the
+	// results are not re-checked.
+	var priceDrops []storage.PriceDrop
+	if search.PriceDropAlerts.Enabled {
+		priceDrops, err = s.db.RecordPrices(ctx, search.ID, targets, matchingItems, search.PriceDropAlerts.MinDropPercent)
 		if err != nil {
-			return nil, fmt.Errorf("failed to post discord notification: %w", err)
+			return nil, fmt.Errorf("failed to record prices: %w", err)
+		}
+
+		slog.Info("Price drops found", "count", len(priceDrops))
+	}
+
+	if len(newItems) > 0 || len(priceDrops) > 0 {
+		if err := s.outbox.DeliverDue(ctx); err != nil {
+			slog.Warn("Error delivering notifications, will retry", "search_id", search.ID, "err", err.Error())
 		}
 	}
 
 	return newItems, nil
 }
 
-func (s *Scraper) getItemsForSearch(search domain.SavedSearch) ([]vinted.Item, error) {
-	items, err := s.vintedClient.GetItems(search.SearchParams)
+// filterItemsForSearch drops items that don't match the search's keyword, seller and price filters
+func (s *Scraper) filterItemsForSearch(ctx context.Context, search domain.SavedSearch, items []vinted.Item) ([]vinted.Item, error) {
+	items, err := filterItemsBySearchFilters(search, items)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get items for search %d: %w", search.ID, err)
+		return nil, err
 	}
-	return items, nil
-}
 
-func (s *Scraper) processItem(search domain.SavedSearch, item vinted.Item) (bool, error) {
-	seen, err := s.db.IsItemSeen(search.ID, int(item.ID))
+	slog.Info("Items remaining after search filters", "count", len(items))
+
+	items, err = s.filterItemsBySeller(ctx, search, items)
 	if err != nil {
-		return false, fmt.Errorf("failed to check if item is seen: %w", err)
+		return nil, err
 	}
 
-	if seen {
-		return false, nil
+	slog.Info("Items remaining after seller filters", "count", len(items))
+
+	if search.Filters.MaxTotalPrice > 0 {
+		items = filterItemsByTotalPrice(items, search.Filters.MaxTotalPrice)
+		slog.Info("Items remaining after total price filter", "count", len(items))
 	}
 
-	err = s.db.MarkItemAsSeen(search.ID, int(item.ID))
+	return items, nil
+}
+
+// getItemsForSearch fetches pages of results, newest first, until reaching an item the search
+// has already observed or one older than the lookback period, so bursts of new listings between
+// polls aren't missed. Observations rather than seen items are checked, as items dropped by the
+// search's filters are never marked as seen.
+func (s *Scraper) getItemsForSearch(ctx context.Context, search domain.SavedSearch) ([]vinted.Item, error) {
+	cutoff := time.Now().Add(-s.config.LookbackPeriod)
+
+	items, err := s.vintedClient.GetItems(ctx, search.SearchParams, vinted.PageOptions{
+		MaxPages: s.config.MaxPages,
+		Stop: func(item vinted.Item) bool {
+			if !s.isItemWithinLookback(item, cutoff) {
+				return true
+			}
+
+			observed, err := s.db.IsItemObserved(ctx, search.ID, item.ID)
+			if err != nil {
+				slog.Warn("Unable to check whether item was observed, stopping pagination", "item_id", item.ID, "err", err.Error())
+				return true
+			}
+			if observed {
+				return true
+			}
+
+			// Items seen before observations were recorded have no history
+			seen, err := s.db.IsItemSeen(ctx, search.ID, int(item.ID))
+			if err != nil {
+				slog.Warn("Unable to check whether item was seen, stopping pagination", "item_id", item.ID, "err", err.Error())
+				return true
+			}
+			return seen
+		},
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to mark item as seen: %w", err)
+		return nil, fmt.Errorf("failed to get items for search %d: %w", search.ID, err)
 	}
-
-	return true, nil
+	return items, nil
 }
 
 // filterItemsByLookback filters items based on the configured lookback period
@@ -184,136 +359,140 @@ func (s *Scraper) isItemWithinLookback(item vinted.Item, cutoff time.Time) bool
 	return uploadedAt.After(cutoff)
 }
 
-func (s *Scraper) postDiscordNotification(items []vinted.Item, search domain.SavedSearch) error {
-	if len(items) == 0 {
-		return nil // No items to notify about
+// filterItemsBySearchFilters drops items that don't match the search's keyword and pattern filters
+func filterItemsBySearchFilters(search domain.SavedSearch, items []vinted.Item) ([]vinted.Item, error) {
+	matcher, err := search.Filters.Matcher()
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters for search %d: %w", search.ID, err)
 	}
 
-	// Split items into batches if needed (Discord has a limit of 10 embeds per message)
-	batches := s.createItemBatches(items, maxEmbedsPerMessage)
-
-	ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
-	defer cancel()
-
-	for i, batch := range batches {
-		if err := s.sendBatch(ctx, batch, search, i, len(batches)); err != nil {
-			return fmt.Errorf("failed to send batch %d: %w", i+1, err)
+	filteredItems := make([]vinted.Item, 0, len(items))
+	for _, item := range items {
+		if matcher.Matches(item.Title, item.BrandTitle) {
+			filteredItems = append(filteredItems, item)
 		}
 	}
 
-	return nil
+	return filteredItems, nil
 }
 
-func (s *Scraper) createItemBatches(items []vinted.Item, batchSize int) [][]vinted.Item {
-	var batches [][]vinted.Item
+// filterItemsBySeller drops items from sellers excluded by the search's or the global seller rules
+func (s *Scraper) filterItemsBySeller(ctx context.Context, search domain.SavedSearch, items []vinted.Item) ([]vinted.Item, error) {
+	blockedSellers, err := s.db.GetBlockedSellers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked sellers: %w", err)
+	}
+
+	globalRules := domain.SellerRules{ExcludeBusiness: s.config.ExcludeBusinessSellers}
+	for _, blocked := range blockedSellers {
+		globalRules.Blocked = append(globalRules.Blocked, blocked.Seller)
+	}
 
-	for i := 0; i < len(items); i += batchSize {
-		end := i + batchSize
-		if end > len(items) {
-			end = len(items)
+	filteredItems := make([]vinted.Item, 0, len(items))
+	for _, item := range items {
+		seller := item.User
+		if globalRules.Allows(seller.ID, seller.Login, seller.Business) && search.Filters.Sellers.Allows(seller.ID, seller.Login, seller.Business) {
+			filteredItems = append(filteredItems, item)
+		} else {
+			slog.Debug("Dropped item from excluded seller", "item_id", item.ID, "seller_id", seller.ID, "seller_login", seller.Login)
 		}
-		batches = append(batches, items[i:end])
 	}
 
-	return batches
+	return filteredItems, nil
 }
 
-func (s *Scraper) sendBatch(ctx context.Context, items []vinted.Item, search domain.SavedSearch, batchNum, totalBatches int) error {
-	message := s.createDiscordMessage(items, search, batchNum, totalBatches)
+// filterItemsByTotalPrice drops items costing more than maxTotalPrice once fees are included.
+// Items whose total can't be determined are kept rather than silently missed.
+func filterItemsByTotalPrice(items []vinted.Item, maxTotalPrice float64) []vinted.Item {
+	filteredItems := make([]vinted.Item, 0, len(items))
+	for _, item := range items {
+		total, err := item.TotalPrice()
+		if err != nil {
+			slog.Warn("Unable to determine total price, keeping item", "item_id", item.ID, "err", err.Error())
+			filteredItems = append(filteredItems, item)
+			continue
+		}
 
-	if err := s.discord.PostMessage(ctx, message); err != nil {
-		return fmt.Errorf("discord API error: %w", err)
+		if total <= maxTotalPrice {
+			filteredItems = append(filteredItems, item)
+		}
 	}
 
-	return nil
+	return filteredItems
 }
 
-func (s *Scraper) createDiscordMessage(items []vinted.Item, search domain.SavedSearch, batchNum, totalBatches int) discord.WebhookMessage {
-	content := s.formatMessageContent(search, len(items), batchNum, totalBatches)
-
-	message := discord.WebhookMessage{
-		Content: content,
-		Embeds:  make([]discord.Embed, 0, len(items)),
-	}
+// scoreDeals scores each item's price against comparable listings, omitting items that can't
+// be scored
+func (s *Scraper) scoreDeals(ctx context.Context, items []vinted.Item) (map[int64]float64, error) {
+	since := time.Now().Add(-market.ComparisonWindow)
 
+	scores := make(map[int64]float64)
 	for _, item := range items {
-		embed := s.createItemEmbed(item)
-		message.Embeds = append(message.Embeds, embed)
-	}
+		price, err := vinted.ParseAmount(item.Price.Amount)
+		if err != nil {
+			continue
+		}
 
-	return message
-}
+		comparables, err := s.db.GetComparablePrices(ctx, item, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get comparable prices for item %d: %w", item.ID, err)
+		}
 
-func (s *Scraper) formatMessageContent(search domain.SavedSearch, itemCount, batchNum, totalBatches int) string {
-	if totalBatches == 1 {
-		return fmt.Sprintf("🔍 **%s**: %d new item(s) found", search.Name, itemCount)
+		if score, ok := market.ScoreDeal(price, comparables); ok {
+			scores[item.ID] = score
+		}
 	}
 
-	return fmt.Sprintf("🔍 **%s**: Batch %d/%d", search.Name, batchNum+1, totalBatches)
+	return scores, nil
 }
 
-func (s *Scraper) createItemEmbed(item vinted.Item) discord.Embed {
-	embed := discord.Embed{
-		Title: s.truncateTitle(item.Title, 256), // Discord title limit
-		URL:   item.URL,
-		Fields: []discord.EmbedField{
-			{
-				Name:   "💰 Price",
-				Value:  s.formatPrice(item.Price),
-				Inline: true,
-			},
-		},
-	}
-
-	// Add size field if available
-	if item.SizeTitle != "" {
-		embed.Fields = append(embed.Fields, discord.EmbedField{
-			Name:   "📏 Size",
-			Value:  item.SizeTitle,
-			Inline: true,
-		})
-	}
-
-	// Add brand field if available
-	if item.BrandTitle != "" {
-		embed.Fields = append(embed.Fields, discord.EmbedField{
-			Name:   "🏷️ Brand",
-			Value:  item.BrandTitle,
-			Inline: true,
-		})
-	}
-
-	// Add image if available
-	if item.Photo.URL != "" {
-		embed.Image = discord.EmbedImage{
-			URL: item.Photo.URL,
+// filterUnseenItems drops items the search has already seen
+func (s *Scraper) filterUnseenItems(ctx context.Context, search domain.SavedSearch, items []vinted.Item) ([]vinted.Item, error) {
+	unseenItems := make([]vinted.Item, 0, len(items))
+	for _, item := range items {
+		seen, err := s.db.IsItemSeen(ctx, search.ID, int(item.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check whether item %d was seen: %w", item.ID, err)
+		}
+		if !seen {
+			unseenItems = append(unseenItems, item)
 		}
 	}
 
-	return embed
+	return unseenItems, nil
 }
 
-func (s *Scraper) formatPrice(price vinted.Price) string {
-	if price.Amount == "" || price.CurrencyCode == "" {
-		return "Price not available"
-	}
+// enrichItems replaces items with their details. Items whose details can't be fetched are
+// notified without them rather than delayed.
+func (s *Scraper) enrichItems(ctx context.Context, search domain.SavedSearch, items []vinted.Item) []vinted.Item {
+	enrichedItems := make([]vinted.Item, 0, len(items))
+	for _, item := range items {
+		details, err := s.vintedClient.GetItemDetails(ctx, search.SearchParams.BaseURL(), item.ID)
+		if err != nil {
+			slog.Warn("Unable to get item details, notifying without them", "item_id", item.ID, "err", err.Error())
+			enrichedItems = append(enrichedItems, item)
+			continue
+		}
 
-	// Handle different currency formats
-	switch price.CurrencyCode {
-	case "EUR":
-		return fmt.Sprintf("€%s", price.Amount)
-	case "USD":
-		return fmt.Sprintf("$%s", price.Amount)
-	case "GBP":
-		return fmt.Sprintf("£%s", price.Amount)
-	default:
-		return fmt.Sprintf("%s %s", price.Amount, price.CurrencyCode)
+		enrichedItems = append(enrichedItems, item.WithDetails(*details))
 	}
+
+	return enrichedItems
 }
 
-func (s *Scraper) truncateTitle(title string, maxLength int) string {
-	if len(title) <= maxLength {
-		return title
+// filterItemsByDealScore drops items priced at or above maxPercentile of comparable listings,
+// returning them separately. Items without a score are kept, as there isn't enough data to
+// judge them.
+func filterItemsByDealScore(items []vinted.Item, scores map[int64]float64, maxPercentile float64) ([]vinted.Item, []vinted.Item) {
+	filteredItems := make([]vinted.Item, 0, len(items))
+	rejectedItems := make([]vinted.Item, 0)
+	for _, item := range items {
+		if score, ok := scores[item.ID]; ok && score >= maxPercentile {
+			rejectedItems = append(rejectedItems, item)
+			continue
+		}
+		filteredItems = append(filteredItems, item)
 	}
-	return title[:maxLength-3] + "..."
+
+	return filteredItems, rejectedItems
 }
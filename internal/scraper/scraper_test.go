@@ -1,3 +1,608 @@
 package scraper
 
-// TODO
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/storage"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVintedClient struct {
+	mu    sync.Mutex
+	items map[string][]vinted.Item
+	// pages, when set for a search, are walked like the real client, stopping at the page
+	// holding the first item matched by PageOptions.Stop
+	pages        map[string][][]vinted.Item
+	pagesFetched int
+	details      map[int64]*vinted.ItemDetails
+	// detailsRequests lists the items whose details were fetched
+	detailsRequests []int64
+	pageOptions     vinted.PageOptions
+	inFlight        atomic.Int32
+	maxInFlight     atomic.Int32
+}
+
+func (f *fakeVintedClient) GetItems(ctx context.Context, params *domain.SearchParams, opts vinted.PageOptions) ([]vinted.Item, error) {
+	current := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		observed := f.maxInFlight.Load()
+		if current <= observed || f.maxInFlight.CompareAndSwap(observed, current) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pageOptions = opts
+	if pages, ok := f.pages[params.SearchText]; ok {
+		return f.walkPages(pages, opts), nil
+	}
+
+	items, ok := f.items[params.SearchText]
+	if !ok {
+		return nil, fmt.Errorf("unknown search %q", params.SearchText)
+	}
+	return items, nil
+}
+
+func (f *fakeVintedClient) walkPages(pages [][]vinted.Item, opts vinted.PageOptions) []vinted.Item {
+	var items []vinted.Item
+	for i, page := range pages {
+		if opts.MaxPages > 0 && i >= opts.MaxPages {
+			break
+		}
+
+		f.pagesFetched++
+		items = append(items, page...)
+		for _, item := range page {
+			if opts.Stop != nil && opts.Stop(item) {
+				return items
+			}
+		}
+	}
+	return items
+}
+
+func (f *fakeVintedClient) GetItemDetails(ctx context.Context, baseURL string, id int64) (*vinted.ItemDetails, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.detailsRequests = append(f.detailsRequests, id)
+	details, ok := f.details[id]
+	if !ok {
+		return nil, vinted.ErrItemNotFound
+	}
+	return details, nil
+}
+
+func newTestItem(id int64) vinted.Item {
+	return vinted.Item{
+		ID:    id,
+		Title: fmt.Sprintf("item %d", id),
+		Photo: vinted.ItemPhoto{
+			HighResolution: vinted.HighResolution{Timestamp: int(time.Now().Unix())},
+		},
+	}
+}
+
+func setupTestDB(t *testing.T) *storage.DB {
+	t.Helper()
+
+	db, err := storage.NewDB(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func createSearches(t *testing.T, db *storage.DB, searchTexts ...string) []domain.SavedSearch {
+	t.Helper()
+
+	searches := make([]domain.SavedSearch, 0, len(searchTexts))
+	for _, searchText := range searchTexts {
+		id, err := db.CreateSearch(context.Background(), domain.NewSavedSearch(&domain.SearchParams{SearchText: searchText}))
+		require.NoError(t, err)
+
+		search, err := db.GetSearchByID(context.Background(), id)
+		require.NoError(t, err)
+		searches = append(searches, *search)
+	}
+	return searches
+}
+
+func Test_ScrapeSearches_ProcessesSearchesConcurrently(t *testing.T) {
+	db := setupTestDB(t)
+
+	client := &fakeVintedClient{items: make(map[string][]vinted.Item)}
+	searchTexts := make([]string, 0)
+	for i := 0; i < 8; i++ {
+		searchText := fmt.Sprintf("search %d", i)
+		searchTexts = append(searchTexts, searchText)
+		client.items[searchText] = []vinted.Item{newTestItem(int64(i*10 + 1)), newTestItem(int64(i*10 + 2))}
+	}
+	searches := createSearches(t, db, searchTexts...)
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour, Concurrency: 3})
+
+	result := s.ScrapeSearches(context.Background(), searches)
+
+	assert.Equal(t, 8, result.ProcessedSearches)
+	assert.Len(t, result.NewItems, 16)
+	assert.Empty(t, result.Errors)
+	assert.LessOrEqual(t, client.maxInFlight.Load(), int32(3))
+	assert.Greater(t, client.maxInFlight.Load(), int32(1))
+
+	// A second run finds nothing new
+	result = s.ScrapeSearches(context.Background(), searches)
+	assert.Equal(t, 8, result.ProcessedSearches)
+	assert.Empty(t, result.NewItems)
+}
+
+func Test_ScrapeSearches_CollectsErrors(t *testing.T) {
+	db := setupTestDB(t)
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{
+		"known": {newTestItem(1)},
+	}}
+	searches := createSearches(t, db, "known", "unknown")
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour})
+
+	result := s.ScrapeSearches(context.Background(), searches)
+
+	assert.Equal(t, 1, result.ProcessedSearches)
+	assert.Len(t, result.NewItems, 1)
+	assert.Len(t, result.Errors, 1)
+
+	for _, search := range searches {
+		updated, err := db.GetSearchByID(context.Background(), search.ID)
+		require.NoError(t, err)
+		assert.False(t, updated.LastChecked.IsZero(), "last checked should be updated even when the search fails")
+	}
+}
+
+func Test_ScrapeSearches_ConcurrentRunsDoNotDuplicateItems(t *testing.T) {
+	db := setupTestDB(t)
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{
+		"search": {newTestItem(1), newTestItem(2), newTestItem(3)},
+	}}
+	searches := createSearches(t, db, "search")
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour})
+
+	var wg sync.WaitGroup
+	var total atomic.Int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := s.ScrapeSearches(context.Background(), searches)
+			total.Add(int32(len(result.NewItems)))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(3), total.Load())
+}
+
+func Test_ScrapeSearches_StopsWhenContextCancelled(t *testing.T) {
+	db := setupTestDB(t)
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{
+		"search": {newTestItem(1)},
+	}}
+	searches := createSearches(t, db, "search")
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := s.ScrapeSearches(ctx, searches)
+	assert.Equal(t, 0, result.ProcessedSearches)
+	assert.Empty(t, result.NewItems)
+}
+
+func Test_ScrapeSearches_RoutesNotificationsPerSearch(t *testing.T) {
+	db := setupTestDB(t)
+
+	newWebhookServer := func(received *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	}
+
+	var kidsReceived, barbourReceived, defaultReceived atomic.Int32
+	kidsServer := newWebhookServer(&kidsReceived)
+	defer kidsServer.Close()
+	barbourServer := newWebhookServer(&barbourReceived)
+	defer barbourServer.Close()
+	defaultServer := newWebhookServer(&defaultReceived)
+	defer defaultServer.Close()
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{
+		"kids clothes":     {newTestItem(1)},
+		"vintage barbour":  {newTestItem(2)},
+		"no custom target": {newTestItem(3)},
+	}}
+	searches := createSearches(t, db, "kids clothes", "vintage barbour", "no custom target")
+	searches[0].NotificationTargets = []domain.NotificationTarget{{Type: domain.NotificationTargetDiscord, URL: kidsServer.URL}}
+	searches[1].NotificationTargets = []domain.NotificationTarget{{Type: domain.NotificationTargetSlack, URL: barbourServer.URL}}
+
+	s := NewScraper(client, db, ScraperConfig{
+		LookbackPeriod:             time.Hour,
+		DefaultNotificationTargets: []domain.NotificationTarget{{Type: domain.NotificationTargetDiscord, URL: defaultServer.URL}},
+	})
+
+	result := s.ScrapeSearches(context.Background(), searches)
+	require.Empty(t, result.Errors)
+
+	assert.Equal(t, int32(1), kidsReceived.Load())
+	assert.Equal(t, int32(1), barbourReceived.Load())
+	assert.Equal(t, int32(1), defaultReceived.Load())
+}
+
+func Test_ProcessSearch_AppliesSearchFilters(t *testing.T) {
+	db := setupTestDB(t)
+
+	genuine := newTestItem(1)
+	genuine.Title, genuine.BrandTitle = "Bedale wax jacket", "Barbour"
+	lookalike := newTestItem(2)
+	lookalike.Title, lookalike.BrandTitle = "Barbour style wax jacket", "Zara"
+	unrelated := newTestItem(3)
+	unrelated.Title, unrelated.BrandTitle = "Barbour gilet", "Barbour"
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{
+		"barbour": {genuine, lookalike, unrelated},
+	}}
+	search := createSearches(t, db, "barbour")[0]
+	search.Filters = domain.ItemFilters{
+		IncludeWords: []string{"jacket"},
+		BrandPattern: "(?i)^barbour$",
+	}
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour})
+
+	newItems, err := s.processSearch(context.Background(), search)
+	require.NoError(t, err)
+	require.Len(t, newItems, 1)
+	assert.Equal(t, genuine.ID, newItems[0].ID)
+}
+
+func Test_ProcessSearch_AppliesSellerRules(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	newSellerItem := func(id int64, sellerID int, login string, business bool) vinted.Item {
+		item := newTestItem(id)
+		item.User = vinted.User{ID: sellerID, Login: login, Business: business}
+		return item
+	}
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{
+		"barbour": {
+			newSellerItem(1, 10, "anna", false),
+			newSellerItem(2, 20, "vintage_shop", true),
+			newSellerItem(3, 30, "Flipper", false),
+			newSellerItem(4, 40, "bob", false),
+		},
+	}}
+	search := createSearches(t, db, "barbour")[0]
+	search.Filters.Sellers = domain.SellerRules{Blocked: []string{"40"}}
+	require.NoError(t, db.BlockSeller(ctx, "flipper", "reseller"))
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour, ExcludeBusinessSellers: true})
+
+	newItems, err := s.processSearch(ctx, search)
+	require.NoError(t, err)
+	require.Len(t, newItems, 1)
+	assert.Equal(t, int64(1), newItems[0].ID)
+}
+
+func Test_ProcessSearch_AppliesMaxTotalPrice(t *testing.T) {
+	db := setupTestDB(t)
+
+	newPricedItem := func(id int64, price, total string) vinted.Item {
+		item := newTestItem(id)
+		item.Price = vinted.Price{Amount: price, CurrencyCode: "GBP"}
+		item.TotalItemPrice = vinted.TotalItemPrice{Amount: total, CurrencyCode: "GBP"}
+		return item
+	}
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{
+		"barbour": {
+			newPricedItem(1, "45.00", "48.00"),
+			newPricedItem(2, "49.00", "52.15"),
+			newPricedItem(3, "", ""),
+		},
+	}}
+	search := createSearches(t, db, "barbour")[0]
+	search.Filters.MaxTotalPrice = 50
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour})
+
+	newItems, err := s.processSearch(context.Background(), search)
+	require.NoError(t, err)
+	require.Len(t, newItems, 2)
+	assert.Equal(t, int64(1), newItems[0].ID)
+	assert.Equal(t, int64(3), newItems[1].ID, "items without a known total are kept")
+}
+
+func Test_ProcessSearch_NotifiesPriceDrops(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var messages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		messages = append(messages, string(body))
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	setPrice := func(client *fakeVintedClient, amount string) {
+		item := newTestItem(1)
+		item.Price = vinted.Price{Amount: amount, CurrencyCode: "GBP"}
+		client.mu.Lock()
+		client.items["barbour"] = []vinted.Item{item}
+		client.mu.Unlock()
+	}
+
+	client := &fakeVintedClient{items: make(map[string][]vinted.Item)}
+	search := createSearches(t, db, "barbour")[0]
+	search.NotificationTargets = []domain.NotificationTarget{{Type: domain.NotificationTargetSlack, URL: server.URL}}
+	search.PriceDropAlerts = domain.PriceDropAlerts{Enabled: true, MinDropPercent: 10}
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour})
+
+	setPrice(client, "50.00")
+	newItems, err := s.processSearch(ctx, search)
+	require.NoError(t, err)
+	require.Len(t, newItems, 1)
+
+	setPrice(client, "40.00")
+	newItems, err = s.processSearch(ctx, search)
+	require.NoError(t, err)
+	assert.Empty(t, newItems)
+
+	// A reduction below the minimum percentage isn't worth a notification
+	setPrice(client, "39.00")
+	_, err = s.processSearch(ctx, search)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, messages, 2)
+	assert.Contains(t, messages[0], "1 new item(s) found")
+	assert.Contains(t, messages[1], "1 price drop(s)")
+	assert.Contains(t, messages[1], "from £50.00 to £40.00")
+}
+
+func Test_ProcessSearch_OnlyNotifiesGoodDeals(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	newJacket := func(id int64, amount string) vinted.Item {
+		item := newTestItem(id)
+		item.BrandTitle, item.SizeTitle = "Barbour", "M"
+		item.Price = vinted.Price{Amount: amount, CurrencyCode: "GBP"}
+		return item
+	}
+
+	// Build up market history from another search
+	history := createSearches(t, db, "history")[0]
+	comparables := make([]vinted.Item, 0)
+	for i := int64(1); i <= 10; i++ {
+		comparables = append(comparables, newJacket(100+i, fmt.Sprintf("%d.00", i*10)))
+	}
+	require.NoError(t, db.RecordObservations(ctx, history.ID, comparables))
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{
+		"barbour": {newJacket(1, "15.00"), newJacket(2, "95.00"), newTestItem(3)},
+	}}
+	search := createSearches(t, db, "barbour")[0]
+	search.Filters.MaxDealPercentile = 25
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour})
+
+	newItems, err := s.processSearch(ctx, search)
+	require.NoError(t, err)
+	require.Len(t, newItems, 2)
+	assert.Equal(t, int64(1), newItems[0].ID)
+	assert.Equal(t, int64(3), newItems[1].ID, "items without comparables are kept")
+
+	// Items the deal filter rejects are seen, so they don't become deals just because the
+	// comparable listings changed
+	seen, err := db.IsItemSeen(ctx, search.ID, 2)
+	require.NoError(t, err)
+	assert.True(t, seen)
+
+	pricier := make([]vinted.Item, 0)
+	for i := int64(1); i <= 30; i++ {
+		pricier = append(pricier, newJacket(200+i, "500.00"))
+	}
+	require.NoError(t, db.RecordObservations(ctx, history.ID, pricier))
+
+	newItems, err = s.processSearch(ctx, search)
+	require.NoError(t, err)
+	assert.Empty(t, newItems)
+}
+
+func Test_CheckAvailability_RecordsSoldAndRemovedItems(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var messages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		messages = append(messages, string(body))
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{
+		"barbour": {newTestItem(1), newTestItem(2), newTestItem(3)},
+	}}
+	search := createSearches(t, db, "barbour")[0]
+	search.NotificationTargets = []domain.NotificationTarget{{Type: domain.NotificationTargetSlack, URL: server.URL}}
+	require.NoError(t, db.UpdateSearch(ctx, &search))
+
+	s := NewScraper(client, db, ScraperConfig{
+		LookbackPeriod: time.Hour,
+		Availability:   AvailabilityConfig{NotifySold: true},
+	})
+
+	_, err := s.processSearch(ctx, search)
+	require.NoError(t, err)
+
+	// Item 1 is still listed, item 2 has sold and item 3 has been deleted
+	client.details = map[int64]*vinted.ItemDetails{
+		1: {Item: vinted.Item{ID: 1, Title: "item 1", IsVisible: true}},
+		2: {Item: vinted.Item{ID: 2, Title: "item 2"}, IsClosed: true, ItemClosingAction: "sold"},
+	}
+
+	result, err := s.CheckAvailability(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &AvailabilityResult{Checked: 3, Sold: 1, Removed: 1}, result)
+
+	sold, err := db.GetItem(ctx, 2)
+	require.NoError(t, err)
+	assert.NotNil(t, sold.SoldAt)
+
+	removed, err := db.GetItem(ctx, 3)
+	require.NoError(t, err)
+	assert.NotNil(t, removed.RemovedAt)
+
+	// Nothing is due again until the re-check period has passed
+	result, err = s.CheckAvailability(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, result.Checked)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, messages, 2)
+	assert.Contains(t, messages[0], "3 new item(s) found")
+	assert.Contains(t, messages[1], "1 watched item(s) sold")
+	assert.Contains(t, messages[1], "item 2")
+}
+
+func Test_ProcessSearch_EnrichesNewItems(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	client := &fakeVintedClient{
+		items: map[string][]vinted.Item{"barbour": {newTestItem(1), newTestItem(2)}},
+		details: map[int64]*vinted.ItemDetails{
+			1: {ListingDetails: vinted.ListingDetails{Description: "Waxed jacket"}},
+		},
+	}
+	search := createSearches(t, db, "barbour")[0]
+	search.NotificationTargets = []domain.NotificationTarget{{Type: domain.NotificationTargetDiscord, URL: "http://127.0.0.1:0"}}
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour, EnrichNewItems: true})
+
+	newItems, err := s.processSearch(ctx, search)
+	require.NoError(t, err)
+	require.Len(t, newItems, 2)
+	require.NotNil(t, newItems[0].Details)
+	assert.Equal(t, "Waxed jacket", newItems[0].Details.Description)
+	assert.Nil(t, newItems[1].Details, "items without details should still be notified")
+
+	// Queued notifications carry the details
+	due, err := db.GetDueOutboxEntries(ctx, time.Now().Add(time.Hour), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	require.NotNil(t, due[0].Item.Details)
+	assert.Equal(t, "Waxed jacket", due[0].Item.Details.Description)
+}
+
+func Test_GetItemsForSearch_StopsAtObservedItemsDroppedByFilters(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	pages := make([][]vinted.Item, 0)
+	for page := int64(0); page < 10; page++ {
+		pages = append(pages, []vinted.Item{newTestItem(page*2 + 1), newTestItem(page*2 + 2)})
+	}
+	client := &fakeVintedClient{pages: map[string][][]vinted.Item{"barbour": pages}}
+	search := createSearches(t, db, "barbour")[0]
+	// Every item is filtered out, so none are ever marked as seen
+	search.Filters = domain.ItemFilters{IncludeWords: []string{"jacket"}}
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour})
+
+	newItems, err := s.processSearch(ctx, search)
+	require.NoError(t, err)
+	assert.Empty(t, newItems)
+	assert.Equal(t, DefaultMaxPages, client.pagesFetched, "the first poll has nothing to stop at")
+
+	client.pagesFetched = 0
+	_, err = s.processSearch(ctx, search)
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.pagesFetched, "paging should stop at the first page of already observed items")
+}
+
+func Test_ProcessSearch_OnlyEnrichesUnseenItems(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{"barbour": {newTestItem(1)}}}
+	search := createSearches(t, db, "barbour")[0]
+	search.NotificationTargets = []domain.NotificationTarget{{Type: domain.NotificationTargetDiscord, URL: "http://127.0.0.1:0"}}
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour, EnrichNewItems: true})
+
+	_, err := s.processSearch(ctx, search)
+	require.NoError(t, err)
+
+	client.items["barbour"] = []vinted.Item{newTestItem(2), newTestItem(1)}
+	newItems, err := s.processSearch(ctx, search)
+	require.NoError(t, err)
+	require.Len(t, newItems, 1)
+	assert.Equal(t, int64(2), newItems[0].ID)
+
+	assert.Equal(t, []int64{1, 2}, client.detailsRequests, "items seen on an earlier poll should not be fetched again")
+}
+
+func Test_GetItemsForSearch_PagesUntilSeenOrOldItems(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	client := &fakeVintedClient{items: map[string][]vinted.Item{"barbour": {newTestItem(1)}}}
+	search := createSearches(t, db, "barbour")[0]
+
+	s := NewScraper(client, db, ScraperConfig{LookbackPeriod: time.Hour})
+
+	_, err := s.processSearch(ctx, search)
+	require.NoError(t, err)
+
+	opts := client.pageOptions
+	assert.Equal(t, DefaultMaxPages, opts.MaxPages)
+	require.NotNil(t, opts.Stop)
+
+	oldItem := newTestItem(3)
+	oldItem.Photo.HighResolution.Timestamp = int(time.Now().Add(-2 * time.Hour).Unix())
+
+	assert.True(t, opts.Stop(newTestItem(1)), "already seen items should stop paging")
+	assert.False(t, opts.Stop(newTestItem(2)))
+	assert.True(t, opts.Stop(oldItem), "items older than the lookback period should stop paging")
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"vinted-watcher/internal/storage"
+)
+
+// BlockSellerRequest adds a seller to the global blocklist
+type BlockSellerRequest struct {
+	// Seller is the seller's Vinted user ID or login
+	Seller string `json:"seller"`
+	Reason string `json:"reason"`
+}
+
+// TODO: Unit Test
+func (s *HTTPServer) ListBlockedSellersHandler(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Listing blocked sellers")
+	sellers, err := s.Storage.GetBlockedSellers(r.Context())
+	if err != nil {
+		writeInternalError(w, "Failed to list blocked sellers", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sellers)
+}
+
+// TODO: Unit Test
+func (s *HTTPServer) BlockSellerHandler(w http.ResponseWriter, r *http.Request) {
+	var req BlockSellerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Seller) == "" {
+		http.Error(w, "Missing seller", http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Blocking seller", "seller", req.Seller)
+	if err := s.Storage.BlockSeller(r.Context(), req.Seller, req.Reason); err != nil {
+		writeInternalError(w, "Failed to block seller", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TODO: Unit Test
+func (s *HTTPServer) UnblockSellerHandler(w http.ResponseWriter, r *http.Request) {
+	seller := r.PathValue("seller")
+
+	slog.Info("Unblocking seller", "seller", seller)
+	if err := s.Storage.UnblockSeller(r.Context(), seller); err != nil {
+		if errors.Is(err, storage.ErrSellerNotBlocked) {
+			http.Error(w, "Seller not blocked", http.StatusNotFound)
+			return
+		}
+		writeInternalError(w, "Failed to unblock seller", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,18 +2,31 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"vinted-watcher/internal/domain"
 	"vinted-watcher/internal/vinted"
 )
 
 type CreateAlertRequest struct {
 	URL string `json:"url"`
+	// Interval is a duration string such as "2m" or "6h". Defaults to hourly.
+	Interval string `json:"interval"`
+	// NotificationTargets routes this search's notifications. Defaults to the global targets.
+	NotificationTargets []domain.NotificationTarget `json:"notification_targets"`
+	// Filters narrow down fetched items by keyword or regular expression
+	Filters domain.ItemFilters `json:"filters"`
+	// PriceDropAlerts enables notifications when seen items are reduced in price. Only items on
+	// the pages fetched by a scrape are compared, see domain.PriceDropAlerts.
+	PriceDropAlerts domain.PriceDropAlerts `json:"price_drop_alerts"`
 }
 
 type CreateAlertResponse struct {
 	ID int `json:"id"`
+	// Warnings describe parts of the URL that were not understood and are ignored by the search
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // TODO: Unit Test
@@ -37,10 +50,38 @@ func (s *HTTPServer) CreateSearchHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	savedSearch := domain.NewSavedSearch(searchParams)
+	savedSearch.OriginalURL = req.URL
 
-	searchID, err := s.Storage.CreateSearch(savedSearch)
+	if req.Interval != "" {
+		interval, err := domain.ParseInterval(req.Interval)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		savedSearch.Interval = interval
+	}
+
+	if err := s.Scraper.ValidateNotificationTargets(req.NotificationTargets); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	savedSearch.NotificationTargets = req.NotificationTargets
+
+	if err := req.Filters.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	savedSearch.Filters = req.Filters
+
+	if err := req.PriceDropAlerts.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	savedSearch.PriceDropAlerts = req.PriceDropAlerts
+
+	searchID, err := s.Storage.CreateSearch(r.Context(), savedSearch)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, "Failed to create search", err)
 		return
 	}
 
@@ -48,6 +89,10 @@ func (s *HTTPServer) CreateSearchHandler(w http.ResponseWriter, r *http.Request)
 		ID: searchID,
 	}
 
+	if unknown := vinted.UnknownParams(req.URL); len(unknown) > 0 {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("unknown parameters ignored: %s", strings.Join(unknown, ", ")))
+	}
+
 	slog.Info("Successfully created new search", slog.Int("id", searchID))
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"time"
+	"vinted-watcher/internal/notifier"
+	"vinted-watcher/internal/storage"
+	"vinted-watcher/internal/vinted"
+)
+
+// DeadLetterResponse is a dead-lettered notification as returned by the API. The target is
+// reduced to its type so its secrets are never exposed.
+type DeadLetterResponse struct {
+	ID            int
+	SearchID      int
+	TargetType    string
+	Item          vinted.Item
+	PreviousPrice *vinted.Price
+	DealScore     *float64
+	TimeToSell    *time.Duration
+	Attempts      int
+	LastError     string
+	DeadAt        *time.Time
+	CreatedAt     time.Time
+}
+
+func newDeadLetterResponse(entry storage.OutboxEntry) DeadLetterResponse {
+	return DeadLetterResponse{
+		ID:            entry.ID,
+		SearchID:      entry.SearchID,
+		TargetType:    entry.Target.Type,
+		Item:          entry.Item,
+		PreviousPrice: entry.PreviousPrice,
+		DealScore:     entry.DealScore,
+		TimeToSell:    entry.TimeToSell,
+		Attempts:      entry.Attempts,
+		LastError:     notifier.RedactText(entry.Target, entry.LastError),
+		DeadAt:        entry.DeadAt,
+		CreatedAt:     entry.CreatedAt,
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"vinted-watcher/internal/storage"
+)
+
+// TODO: Unit Test
+func (s *HTTPServer) ListDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Listing dead-lettered notifications")
+	entries, err := s.Storage.GetDeadLetters(r.Context())
+	if err != nil {
+		writeInternalError(w, "Failed to list dead letters", err)
+		return
+	}
+
+	responses := make([]DeadLetterResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, newDeadLetterResponse(entry))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+// TODO: Unit Test
+func (s *HTTPServer) ReplayDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	entryID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid dead letter ID", http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Replaying dead-lettered notification", slog.Int("id", entryID))
+	if err := s.Storage.ReplayDeadLetter(r.Context(), entryID); err != nil {
+		if errors.Is(err, storage.ErrOutboxEntryNotFound) {
+			http.Error(w, "Dead letter not found", http.StatusNotFound)
+			return
+		}
+		writeInternalError(w, "Failed to replay dead letter", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
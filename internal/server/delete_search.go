@@ -0,0 +1,24 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// TODO: Unit Test
+func (s *HTTPServer) DeleteSearchHandler(w http.ResponseWriter, r *http.Request) {
+	searchID, err := parseSearchID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Deleting search", slog.Int("id", searchID))
+	if err := s.Storage.DeleteSearch(r.Context(), searchID); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	slog.Info("Successfully deleted search", slog.Int("id", searchID))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"vinted-watcher/internal/storage"
+)
+
+// ItemHistoryResponse is an item's latest known state along with every observation of it
+type ItemHistoryResponse struct {
+	Item *storage.ItemRecord `json:"item"`
+	// TimeToSellSeconds is set once the item has been found sold
+	TimeToSellSeconds *int64                    `json:"time_to_sell_seconds,omitempty"`
+	Observations      []storage.ItemObservation `json:"observations"`
+}
+
+func (s *HTTPServer) GetItemHandler(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Getting item", slog.Int64("id", itemID))
+	item, err := s.Storage.GetItem(r.Context(), itemID)
+	if err != nil {
+		writeInternalError(w, "Error getting item", err)
+		return
+	}
+
+	if item == nil {
+		http.Error(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	observations, err := s.Storage.GetItemObservations(r.Context(), itemID)
+	if err != nil {
+		writeInternalError(w, "Error getting item observations", err)
+		return
+	}
+
+	response := ItemHistoryResponse{Item: item, Observations: observations}
+	if timeToSell, ok := item.TimeToSell(); ok {
+		seconds := int64(timeToSell.Seconds())
+		response.TimeToSellSeconds = &seconds
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/storage"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestServer(t *testing.T) *HTTPServer {
+	t.Helper()
+
+	db, err := storage.NewDB(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return NewServer(db, nil)
+}
+
+func getItem(s *HTTPServer, id string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+	r.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	s.GetItemHandler(w, r)
+	return w
+}
+
+func Test_GetItemHandler_ReturnsHistoryAndTimeToSell(t *testing.T) {
+	s := setupTestServer(t)
+	ctx := context.Background()
+
+	searchID, err := s.Storage.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	item := vinted.Item{ID: 42, Title: "Bedale", Price: vinted.Price{Amount: "40.00", CurrencyCode: "GBP"}}
+	require.NoError(t, s.Storage.RecordObservations(ctx, searchID, []vinted.Item{item}))
+
+	w := getItem(s, "42")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response ItemHistoryResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.NotNil(t, response.Item)
+	assert.Equal(t, "Bedale", response.Item.Title)
+	require.Len(t, response.Observations, 1)
+	assert.Equal(t, 40.0, *response.Observations[0].Price)
+	assert.Nil(t, response.TimeToSellSeconds, "unsold items have no time to sell")
+
+	_, err = s.Storage.MarkItemSold(ctx, item, nil)
+	require.NoError(t, err)
+
+	w = getItem(s, "42")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	response = ItemHistoryResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.NotNil(t, response.TimeToSellSeconds)
+	assert.GreaterOrEqual(t, *response.TimeToSellSeconds, int64(0))
+	assert.NotNil(t, response.Item.SoldAt)
+}
+
+func Test_GetItemHandler_RejectsInvalidID(t *testing.T) {
+	s := setupTestServer(t)
+
+	w := getItem(s, "not-a-number")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_GetItemHandler_ReturnsNotFound(t *testing.T) {
+	s := setupTestServer(t)
+
+	w := getItem(s, "404")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetItemHandler_HidesStorageErrors(t *testing.T) {
+	s := setupTestServer(t)
+	require.NoError(t, s.Storage.Close())
+
+	w := getItem(s, "42")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "Internal server error\n", w.Body.String())
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"vinted-watcher/internal/market"
+)
+
+// TODO: Unit Test
+func (s *HTTPServer) GetSearchStatsHandler(w http.ResponseWriter, r *http.Request) {
+	searchID, err := parseSearchID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Getting search stats", slog.Int("id", searchID))
+	search, err := s.Storage.GetSearchByID(r.Context(), searchID)
+	if err != nil {
+		writeInternalError(w, "Failed to get search", err)
+		return
+	}
+
+	if search == nil {
+		http.Error(w, "Search not found", http.StatusNotFound)
+		return
+	}
+
+	listings, err := s.Storage.GetSearchListings(r.Context(), searchID)
+	if err != nil {
+		writeInternalError(w, "Failed to get search listings", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(market.ComputeSearchStats(searchID, listings))
+}
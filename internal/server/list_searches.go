@@ -9,14 +9,19 @@ import (
 // TODO: Unit Test
 func (s *HTTPServer) ListSearchesHandler(w http.ResponseWriter, r *http.Request) {
 	slog.Info("Listing all searches")
-	searches, err := s.Storage.GetAllSearches()
+	searches, err := s.Storage.GetAllSearches(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeInternalError(w, "Failed to list searches", err)
 		return
 	}
 
 	slog.Info("Successfully retrieved all searches", "count", len(searches))
 
+	responses := make([]SearchResponse, 0, len(searches))
+	for _, search := range searches {
+		responses = append(responses, newSearchResponse(search))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(searches)
+	json.NewEncoder(w).Encode(responses)
 }
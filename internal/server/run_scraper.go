@@ -5,7 +5,7 @@ import (
 )
 
 func (s *HTTPServer) RunScraperHandler(w http.ResponseWriter, r *http.Request) {
-	_, err := s.Scraper.Scrape()
+	_, err := s.Scraper.Scrape(r.Context())
 	if err != nil {
 		http.Error(w, "Failed to run scraper", http.StatusInternalServerError)
 		return
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/notifier"
+)
+
+// SearchResponse is a saved search as returned by the API, with a link back to it on Vinted.
+// Notification target secrets are redacted.
+type SearchResponse struct {
+	*domain.SavedSearch
+	WebURL string `json:"WebURL,omitempty"`
+}
+
+func newSearchResponse(search *domain.SavedSearch) SearchResponse {
+	redactedSearch := *search
+	redactedSearch.NotificationTargets = notifier.RedactTargets(search.NotificationTargets)
+
+	response := SearchResponse{SavedSearch: &redactedSearch}
+	if search.SearchParams != nil {
+		response.WebURL, _ = search.SearchParams.ToWebURL()
+	}
+	return response
+}
@@ -3,8 +3,10 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 	"vinted-watcher/internal/scraper"
 	"vinted-watcher/internal/storage"
@@ -15,6 +17,18 @@ type Server interface {
 	Shutdown(ctx context.Context) error
 	CreateSearchHandler(w http.ResponseWriter, r *http.Request)
 	ListSearchesHandler(w http.ResponseWriter, r *http.Request)
+	GetSearchHandler(w http.ResponseWriter, r *http.Request)
+	GetSearchStatsHandler(w http.ResponseWriter, r *http.Request)
+	UpdateSearchHandler(w http.ResponseWriter, r *http.Request)
+	DeleteSearchHandler(w http.ResponseWriter, r *http.Request)
+	PauseSearchHandler(w http.ResponseWriter, r *http.Request)
+	ResumeSearchHandler(w http.ResponseWriter, r *http.Request)
+	ListDeadLettersHandler(w http.ResponseWriter, r *http.Request)
+	ReplayDeadLetterHandler(w http.ResponseWriter, r *http.Request)
+	ListBlockedSellersHandler(w http.ResponseWriter, r *http.Request)
+	BlockSellerHandler(w http.ResponseWriter, r *http.Request)
+	UnblockSellerHandler(w http.ResponseWriter, r *http.Request)
+	GetItemHandler(w http.ResponseWriter, r *http.Request)
 }
 
 // TODO: Unit Test
@@ -35,6 +49,18 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.Handle("POST /searches", authMiddleware(http.HandlerFunc(s.CreateSearchHandler)))
 	mux.Handle("GET /searches", authMiddleware(http.HandlerFunc(s.ListSearchesHandler)))
+	mux.Handle("GET /searches/{id}", authMiddleware(http.HandlerFunc(s.GetSearchHandler)))
+	mux.Handle("GET /searches/{id}/stats", authMiddleware(http.HandlerFunc(s.GetSearchStatsHandler)))
+	mux.Handle("PATCH /searches/{id}", authMiddleware(http.HandlerFunc(s.UpdateSearchHandler)))
+	mux.Handle("DELETE /searches/{id}", authMiddleware(http.HandlerFunc(s.DeleteSearchHandler)))
+	mux.Handle("POST /searches/{id}/pause", authMiddleware(http.HandlerFunc(s.PauseSearchHandler)))
+	mux.Handle("POST /searches/{id}/resume", authMiddleware(http.HandlerFunc(s.ResumeSearchHandler)))
+	mux.Handle("GET /notifications/dead-letters", authMiddleware(http.HandlerFunc(s.ListDeadLettersHandler)))
+	mux.Handle("POST /notifications/dead-letters/{id}/replay", authMiddleware(http.HandlerFunc(s.ReplayDeadLetterHandler)))
+	mux.Handle("GET /sellers/blocked", authMiddleware(http.HandlerFunc(s.ListBlockedSellersHandler)))
+	mux.Handle("POST /sellers/blocked", authMiddleware(http.HandlerFunc(s.BlockSellerHandler)))
+	mux.Handle("DELETE /sellers/blocked/{seller}", authMiddleware(http.HandlerFunc(s.UnblockSellerHandler)))
+	mux.Handle("GET /items/{id}", authMiddleware(http.HandlerFunc(s.GetItemHandler)))
 	mux.Handle("POST /scrape", authMiddleware(http.HandlerFunc(s.RunScraperHandler)))
 
 	s.httpServer = &http.Server{
@@ -71,3 +97,26 @@ func (s *HTTPServer) Shutdown(ctx context.Context) error {
 	slog.Info("Shutting down server...")
 	return s.httpServer.Shutdown(ctx)
 }
+
+func parseSearchID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid search ID: %q", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+// writeInternalError logs err and returns a generic error, so storage details aren't exposed
+// to clients
+func writeInternalError(w http.ResponseWriter, message string, err error) {
+	slog.Error(message, "error", err)
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
+
+func writeStorageError(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrSearchNotFound) {
+		http.Error(w, "Search not found", http.StatusNotFound)
+		return
+	}
+	writeInternalError(w, "Storage error", err)
+}
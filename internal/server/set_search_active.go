@@ -0,0 +1,32 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// TODO: Unit Test
+func (s *HTTPServer) PauseSearchHandler(w http.ResponseWriter, r *http.Request) {
+	s.setSearchActive(w, r, false)
+}
+
+// TODO: Unit Test
+func (s *HTTPServer) ResumeSearchHandler(w http.ResponseWriter, r *http.Request) {
+	s.setSearchActive(w, r, true)
+}
+
+func (s *HTTPServer) setSearchActive(w http.ResponseWriter, r *http.Request, active bool) {
+	searchID, err := parseSearchID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Setting search active state", slog.Int("id", searchID), slog.Bool("active", active))
+	if err := s.Storage.SetSearchActive(r.Context(), searchID, active); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
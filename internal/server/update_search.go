@@ -0,0 +1,112 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+)
+
+// UpdateSearchRequest holds the fields that can be changed on a saved search.
+// Omitted fields are left unchanged.
+type UpdateSearchRequest struct {
+	Name                *string                      `json:"name"`
+	URL                 *string                      `json:"url"`
+	Interval            *string                      `json:"interval"`
+	NotificationTargets *[]domain.NotificationTarget `json:"notification_targets"`
+	Filters             *domain.ItemFilters          `json:"filters"`
+	PriceDropAlerts     *domain.PriceDropAlerts      `json:"price_drop_alerts"`
+	Active              *bool                        `json:"active"`
+}
+
+func (s *HTTPServer) UpdateSearchHandler(w http.ResponseWriter, r *http.Request) {
+	searchID, err := parseSearchID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Updating search", slog.Int("id", searchID))
+	var req UpdateSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	search, err := s.Storage.GetSearchByID(r.Context(), searchID)
+	if err != nil {
+		writeInternalError(w, "Failed to get search", err)
+		return
+	}
+
+	if search == nil {
+		http.Error(w, "Search not found", http.StatusNotFound)
+		return
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			http.Error(w, "Name cannot be empty", http.StatusBadRequest)
+			return
+		}
+		search.Name = *req.Name
+	}
+
+	if req.URL != nil {
+		searchParams, err := vinted.ParseVintedURL(*req.URL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		search.SearchParams = searchParams
+		search.OriginalURL = *req.URL
+	}
+
+	if req.Interval != nil {
+		interval, err := domain.ParseInterval(*req.Interval)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		search.Interval = interval
+	}
+
+	if req.NotificationTargets != nil {
+		if err := s.Scraper.ValidateNotificationTargets(*req.NotificationTargets); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		search.NotificationTargets = *req.NotificationTargets
+	}
+
+	if req.Filters != nil {
+		if err := req.Filters.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		search.Filters = *req.Filters
+	}
+
+	if req.PriceDropAlerts != nil {
+		if err := req.PriceDropAlerts.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		search.PriceDropAlerts = *req.PriceDropAlerts
+	}
+
+	if req.Active != nil {
+		search.Active = *req.Active
+	}
+
+	if err := s.Storage.UpdateSearch(r.Context(), search); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	slog.Info("Successfully updated search", slog.Int("id", searchID))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newSearchResponse(search))
+}
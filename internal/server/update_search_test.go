@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"vinted-watcher/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func updateSearch(s *HTTPServer, id string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPatch, "/searches/"+id, strings.NewReader(body))
+	r.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	s.UpdateSearchHandler(w, r)
+	return w
+}
+
+func Test_UpdateSearchHandler_ReturnsSearchResponse(t *testing.T) {
+	s := setupTestServer(t)
+	ctx := context.Background()
+
+	search := domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour", BrandIDs: []int{1}})
+	search.NotificationTargets = []domain.NotificationTarget{{Type: domain.NotificationTargetDiscord, URL: "https://discord.com/api/webhooks/1/abc"}}
+	searchID, err := s.Storage.CreateSearch(ctx, search)
+	require.NoError(t, err)
+
+	w := updateSearch(s, strconv.Itoa(searchID), `{"name": "Barbour jackets", "url": "https://www.vinted.fr/catalog?search_text=barbour&brand_ids[]=2"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response SearchResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, searchID, response.ID)
+	assert.Equal(t, "Barbour jackets", response.Name)
+	assert.Equal(t, "https://www.vinted.fr/catalog?brand_ids%5B%5D=2&order=newest_first&search_text=barbour", response.WebURL)
+	require.Len(t, response.NotificationTargets, 1)
+	assert.Equal(t, "https://discord.com/REDACTED", response.NotificationTargets[0].URL, "target secrets should be redacted")
+
+	stored, err := s.Storage.GetSearchByID(ctx, searchID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://discord.com/api/webhooks/1/abc", stored.NotificationTargets[0].URL, "the stored target should be unchanged")
+}
+
+func Test_UpdateSearchHandler_ReturnsNotFound(t *testing.T) {
+	s := setupTestServer(t)
+
+	w := updateSearch(s, "404", `{"name": "missing"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_UpdateSearchHandler_RejectsEmptyName(t *testing.T) {
+	s := setupTestServer(t)
+
+	searchID, err := s.Storage.CreateSearch(context.Background(), domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	w := updateSearch(s, strconv.Itoa(searchID), `{"name": ""}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
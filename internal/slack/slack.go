@@ -0,0 +1,131 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type SlackWebhook struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// WebhookMessage represents a Slack incoming webhook payload. Text is used as the
+// notification fallback when Blocks are present.
+type WebhookMessage struct {
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks,omitempty"`
+}
+
+// Block is a Block Kit layout block
+type Block struct {
+	Type      string       `json:"type"`
+	Text      *TextObject  `json:"text,omitempty"`
+	Fields    []TextObject `json:"fields,omitempty"`
+	Accessory *ImageBlock  `json:"accessory,omitempty"`
+}
+
+type TextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type ImageBlock struct {
+	Type     string `json:"type"`
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+}
+
+// NewSectionBlock creates a section block with markdown text
+func NewSectionBlock(text string) Block {
+	return Block{
+		Type: "section",
+		Text: &TextObject{Type: "mrkdwn", Text: text},
+	}
+}
+
+// NewDividerBlock creates a divider block
+func NewDividerBlock() Block {
+	return Block{Type: "divider"}
+}
+
+// NewMarkdownText creates a markdown text object
+func NewMarkdownText(text string) TextObject {
+	return TextObject{Type: "mrkdwn", Text: text}
+}
+
+// NewImageAccessory creates an image element for use as a section accessory
+func NewImageAccessory(imageURL, altText string) *ImageBlock {
+	return &ImageBlock{Type: "image", ImageURL: imageURL, AltText: altText}
+}
+
+// NewSlackWebhook creates a new Slack webhook client with sensible defaults
+func NewSlackWebhook(webhookURL string) *SlackWebhook {
+	return &SlackWebhook{
+		webhookURL: webhookURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// NewSlackWebhookWithClient creates a new Slack webhook client with a custom HTTP client
+func NewSlackWebhookWithClient(webhookURL string, client *http.Client) *SlackWebhook {
+	return &SlackWebhook{
+		webhookURL: webhookURL,
+		client:     client,
+	}
+}
+
+func (s SlackWebhook) PostMessage(ctx context.Context, message WebhookMessage) error {
+	if message.Text == "" {
+		return fmt.Errorf("message text cannot be empty")
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		slog.Error("failed to marshal webhook message", "error", err)
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewBuffer(body))
+	if err != nil {
+		slog.Error("failed to create HTTP request", "error", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		slog.Error("error posting to slack", "error", err)
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		slog.Info("message sent successfully to Slack", "status_code", resp.StatusCode)
+		return nil
+	}
+
+	// Slack returns a short plain-text error such as "invalid_blocks"
+	respBody, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		slog.Error("failed to send message to Slack and failed to read error response",
+			"status_code", resp.StatusCode, "read_error", readErr)
+		return fmt.Errorf("failed to send message (status: %d) and failed to read error response: %w",
+			resp.StatusCode, readErr)
+	}
+
+	slog.Error("failed to send message to Slack",
+		"status_code", resp.StatusCode,
+		"response_body", string(respBody))
+
+	return fmt.Errorf("failed to send message (status: %d): %s", resp.StatusCode, string(respBody))
+}
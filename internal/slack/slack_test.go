@@ -0,0 +1,52 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PostMessage_SendsBlocks(t *testing.T) {
+	var received WebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	block := NewSectionBlock("*<https://www.vinted.co.uk/items/1|Barbour jacket>*")
+	block.Fields = []TextObject{NewMarkdownText("*💰 Price*\n£40.00")}
+	block.Accessory = NewImageAccessory("https://images.vinted.net/1.jpg", "Barbour jacket")
+
+	message := WebhookMessage{
+		Text:   "1 new item(s) found",
+		Blocks: []Block{block, NewDividerBlock()},
+	}
+
+	err := NewSlackWebhook(server.URL).PostMessage(context.Background(), message)
+	require.NoError(t, err)
+	assert.Equal(t, message, received)
+}
+
+func Test_PostMessage_ReturnsSlackError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid_blocks"))
+	}))
+	defer server.Close()
+
+	err := NewSlackWebhook(server.URL).PostMessage(context.Background(), WebhookMessage{Text: "hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid_blocks")
+}
+
+func Test_PostMessage_RequiresText(t *testing.T) {
+	err := NewSlackWebhook("http://unused").PostMessage(context.Background(), WebhookMessage{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+)
+
+// DigestItem is an item waiting to be included in the next email digest for a set of recipients
+type DigestItem struct {
+	ID         int
+	Recipients string
+	Period     time.Duration
+	SearchID   int
+	SearchName string
+	Item       vinted.Item
+	// PreviousPrice is set when the item is included for a price drop rather than as a new item
+	PreviousPrice *vinted.Price
+	// DealScore is the percentile of the item's price among comparable listings, if known
+	DealScore *float64
+	QueuedAt  time.Time
+}
+
+type DigestStorage interface {
+	EnqueueDigestItems(ctx context.Context, recipients string, period time.Duration, search domain.SavedSearch, items []vinted.Item, previousPrices map[int64]vinted.Price, dealScores map[int64]float64) error
+	GetPendingDigestItems(ctx context.Context) ([]DigestItem, error)
+	DeleteDigestItems(ctx context.Context, ids []int) error
+}
+
+// EnqueueDigestItems queues items for the recipients' next digest. previousPrices holds the price
+// each item was reduced from when the items are price drops rather than new items, and
+// dealScores holds the deal scores of the items that have one.
+func (d *DB) EnqueueDigestItems(ctx context.Context, recipients string, period time.Duration, search domain.SavedSearch, items []vinted.Item, previousPrices map[int64]vinted.Price, dealScores map[int64]float64) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queuedAt := time.Now().UTC()
+	for _, item := range items {
+		itemJSON, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to marshal item %d: %w", item.ID, err)
+		}
+
+		var previousPriceJSON []byte
+		if previous, ok := previousPrices[item.ID]; ok {
+			previousPriceJSON, err = json.Marshal(previous)
+			if err != nil {
+				return fmt.Errorf("failed to marshal previous price of item %d: %w", item.ID, err)
+			}
+		}
+
+		var dealScore *float64
+		if score, ok := dealScores[item.ID]; ok {
+			dealScore = &score
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO pending_digest_items (recipients, period_seconds, search_id, search_name, item, previous_price, deal_score, queued_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			recipients, int64(period/time.Second), search.ID, search.Name, itemJSON, nullableJSON(previousPriceJSON), dealScore, queuedAt)
+		if err != nil {
+			return fmt.Errorf("failed to execute insert query: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DB) GetPendingDigestItems(ctx context.Context) ([]DigestItem, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT id, recipients, period_seconds, search_id, search_name, item, previous_price, deal_score, queued_at
+        FROM pending_digest_items
+        ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var items []DigestItem
+	for rows.Next() {
+		var item DigestItem
+		var periodSeconds int64
+		var itemJSON string
+		var previousPriceJSON sql.NullString
+		var dealScore sql.NullFloat64
+
+		if err := rows.Scan(&item.ID, &item.Recipients, &periodSeconds, &item.SearchID, &item.SearchName, &itemJSON, &previousPriceJSON, &dealScore, &item.QueuedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		item.Period = time.Duration(periodSeconds) * time.Second
+
+		if err := json.Unmarshal([]byte(itemJSON), &item.Item); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+		}
+
+		if previousPriceJSON.Valid {
+			if err := json.Unmarshal([]byte(previousPriceJSON.String), &item.PreviousPrice); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal previous price: %w", err)
+			}
+		}
+
+		if dealScore.Valid {
+			item.DealScore = &dealScore.Float64
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return items, nil
+}
+
+func (d *DB) DeleteDigestItems(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	_, err := d.conn.ExecContext(ctx, `
+        DELETE FROM pending_digest_items
+        WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	return nil
+}
+
+// nullableJSON stores empty JSON as NULL
+func nullableJSON(value []byte) any {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DigestItemsRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+	search := domain.SavedSearch{ID: searchID, Name: "barbour"}
+
+	items := []vinted.Item{
+		{ID: 1, Title: "Bedale", Price: vinted.Price{Amount: "40.0", CurrencyCode: "GBP"}},
+		{ID: 2, Title: "Beaufort"},
+	}
+	previousPrices := map[int64]vinted.Price{2: {Amount: "60.0", CurrencyCode: "GBP"}}
+	dealScores := map[int64]float64{1: 12.5}
+	require.NoError(t, db.EnqueueDigestItems(ctx, "me@example.com", 24*time.Hour, search, items, previousPrices, dealScores))
+
+	pending, err := db.GetPendingDigestItems(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "me@example.com", pending[0].Recipients)
+	assert.Equal(t, 24*time.Hour, pending[0].Period)
+	assert.Equal(t, searchID, pending[0].SearchID)
+	assert.Equal(t, "barbour", pending[0].SearchName)
+	assert.Equal(t, items[0], pending[0].Item)
+	assert.Nil(t, pending[0].PreviousPrice)
+	assert.Equal(t, &vinted.Price{Amount: "60.0", CurrencyCode: "GBP"}, pending[1].PreviousPrice)
+	require.NotNil(t, pending[0].DealScore)
+	assert.Equal(t, 12.5, *pending[0].DealScore)
+	assert.Nil(t, pending[1].DealScore)
+	assert.False(t, pending[0].QueuedAt.IsZero())
+
+	require.NoError(t, db.DeleteDigestItems(ctx, []int{pending[0].ID}))
+
+	pending, err = db.GetPendingDigestItems(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, int64(2), pending[0].Item.ID)
+
+	// Deleting the search drops its pending digest items
+	require.NoError(t, db.DeleteSearch(ctx, searchID))
+	pending, err = db.GetPendingDigestItems(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
@@ -0,0 +1,463 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+)
+
+// ItemRecord is the latest known state of an item the scraper has observed
+type ItemRecord struct {
+	ID          int64
+	Title       string
+	Brand       string
+	Size        string
+	SellerID    int
+	SellerLogin string
+	PhotoURL    string
+	URL         string
+	Status      string
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+	// AvailabilityCheckedAt, SoldAt and RemovedAt are set once notified items are re-checked
+	AvailabilityCheckedAt *time.Time
+	SoldAt                *time.Time
+	RemovedAt             *time.Time
+}
+
+// TimeToSell returns how long the item was listed before it sold, measured from when it was
+// first seen, and false if it hasn't sold
+func (r ItemRecord) TimeToSell() (time.Duration, bool) {
+	if r.SoldAt == nil {
+		return 0, false
+	}
+	return r.SoldAt.Sub(r.FirstSeenAt), true
+}
+
+// SearchListing summarises an item's history within a single search
+type SearchListing struct {
+	ItemID int64
+	// FirstObservedAt is when the search first observed the item
+	FirstObservedAt time.Time
+	// Price and CurrencyCode are from the search's latest observation of the item. Price is nil
+	// when Vinted didn't report a parseable amount.
+	Price        *float64
+	CurrencyCode string
+	// FirstSeenAt, SoldAt and RemovedAt are from the item's record, see ItemRecord
+	FirstSeenAt time.Time
+	SoldAt      *time.Time
+	RemovedAt   *time.Time
+}
+
+// ItemObservation records an item's price and popularity as seen by a single scrape
+type ItemObservation struct {
+	ID       int
+	ItemID   int64
+	SearchID int
+	// Price and TotalPrice are nil when Vinted didn't report a parseable amount
+	Price          *float64
+	TotalPrice     *float64
+	CurrencyCode   string
+	FavouriteCount int
+	ViewCount      int
+	ObservedAt     time.Time
+}
+
+const itemColumns = "id, title, brand, size, seller_id, seller_login, photo_url, url, status, first_seen_at, last_seen_at"
+
+const itemRecordColumns = itemColumns + ", availability_checked_at, sold_at, removed_at"
+
+const observationColumns = "id, item_id, COALESCE(search_id, 0), price, total_price, currency_code, favourite_count, view_count, observed_at"
+
+// RecordObservations upserts every item and records an observation of each for the search
+func (d *DB) RecordObservations(ctx context.Context, searchID int, items []vinted.Item) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	observedAt := time.Now().UTC()
+	for _, item := range items {
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO items (`+itemColumns+`)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+            ON CONFLICT (id) DO UPDATE SET
+                title = excluded.title,
+                brand = excluded.brand,
+                size = excluded.size,
+                seller_id = excluded.seller_id,
+                seller_login = excluded.seller_login,
+                photo_url = excluded.photo_url,
+                url = excluded.url,
+                status = excluded.status,
+                last_seen_at = excluded.last_seen_at`,
+			item.ID, item.Title, item.BrandTitle, item.SizeTitle, item.User.ID, item.User.Login, item.Photo.URL, item.URL, item.Status, observedAt, observedAt)
+		if err != nil {
+			return fmt.Errorf("failed to upsert item %d: %w", item.ID, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO item_observations (item_id, search_id, price, total_price, currency_code, favourite_count, view_count, observed_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			item.ID, searchID, nullableAmount(item.Price.Amount), nullableAmount(item.TotalItemPrice.Amount), item.Price.CurrencyCode, item.FavouriteCount, item.ViewCount, observedAt)
+		if err != nil {
+			return fmt.Errorf("failed to record observation of item %d: %w", item.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetItem returns the item with the given Vinted ID, or nil if it has never been observed
+func (d *DB) GetItem(ctx context.Context, id int64) (*ItemRecord, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT `+itemRecordColumns+`
+        FROM items
+        WHERE id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanItemRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return &items[0], nil
+}
+
+// GetItemsToRecheck returns items that have been notified to at least one search, haven't sold
+// or been removed, and whose availability hasn't been checked since checkedBefore. Items that
+// have never been checked come first, followed by the least recently checked.
+func (d *DB) GetItemsToRecheck(ctx context.Context, checkedBefore time.Time, limit int) ([]ItemRecord, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT `+itemRecordColumns+`
+        FROM items
+        WHERE sold_at IS NULL
+            AND removed_at IS NULL
+            AND (availability_checked_at IS NULL OR availability_checked_at < ?)
+            AND EXISTS (SELECT 1 FROM seen_items WHERE item_id = items.id)
+        ORDER BY availability_checked_at, id
+        LIMIT ?`, checkedBefore.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	return scanItemRecords(rows)
+}
+
+// GetItemSearchIDs returns the searches that the item has been notified to
+func (d *DB) GetItemSearchIDs(ctx context.Context, itemID int64) ([]int, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT search_id
+        FROM seen_items
+        WHERE item_id = ?
+        ORDER BY search_id`, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	searchIDs := make([]int, 0)
+	for rows.Next() {
+		var searchID int
+		if err := rows.Scan(&searchID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		searchIDs = append(searchIDs, searchID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return searchIDs, nil
+}
+
+// MarkItemAvailable records that a re-check found the item still listed
+func (d *DB) MarkItemAvailable(ctx context.Context, itemID int64) error {
+	_, err := d.conn.ExecContext(ctx, `
+        UPDATE items
+        SET availability_checked_at = ?
+        WHERE id = ?`, time.Now().UTC(), itemID)
+	if err != nil {
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	return nil
+}
+
+// MarkItemRemoved records that a re-check found the item deleted or hidden without being sold
+func (d *DB) MarkItemRemoved(ctx context.Context, itemID int64) error {
+	now := time.Now().UTC()
+	_, err := d.conn.ExecContext(ctx, `
+        UPDATE items
+        SET availability_checked_at = ?, removed_at = COALESCE(removed_at, ?)
+        WHERE id = ?`, now, now, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	return nil
+}
+
+// MarkItemSold records that a re-check found the item sold and, in the same transaction, queues
+// a notification to each search's targets. It returns how long the item took to sell.
+func (d *DB) MarkItemSold(ctx context.Context, item vinted.Item, targets map[int][]domain.NotificationTarget) (time.Duration, error) {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := tx.ExecContext(ctx, `
+        UPDATE items
+        SET availability_checked_at = ?, sold_at = ?
+        WHERE id = ? AND sold_at IS NULL`, now, now, item.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	var firstSeenAt, soldAt time.Time
+	err = tx.QueryRowContext(ctx, `
+        SELECT first_seen_at, sold_at
+        FROM items
+        WHERE id = ?`, item.ID).Scan(&firstSeenAt, &soldAt)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("item %d has never been observed", item.ID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	timeToSell := soldAt.Sub(firstSeenAt)
+
+	// Only the re-check that first finds the item sold announces it
+	if rowsAffected > 0 {
+		for searchID, searchTargets := range targets {
+			targetsJSON, err := marshalOutboxTargets(searchTargets)
+			if err != nil {
+				return 0, err
+			}
+
+			if err := enqueueOutboxEntries(ctx, tx, searchID, targetsJSON, item, nil, nil, &timeToSell, now); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return timeToSell, nil
+}
+
+// IsItemObserved reports whether the search has observed the item before, whether or not it
+// matched the search's filters
+func (d *DB) IsItemObserved(ctx context.Context, searchID int, itemID int64) (bool, error) {
+	var observed bool
+	err := d.conn.QueryRowContext(ctx, `
+        SELECT EXISTS(
+            SELECT 1
+            FROM item_observations
+            WHERE item_id = ? AND search_id = ?
+        )`, itemID, searchID).Scan(&observed)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to execute select query: %w", err)
+	}
+
+	return observed, nil
+}
+
+// GetItemObservations returns every observation of the item, oldest first
+func (d *DB) GetItemObservations(ctx context.Context, itemID int64) ([]ItemObservation, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT `+observationColumns+`
+        FROM item_observations
+        WHERE item_id = ?
+        ORDER BY observed_at, id`, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObservations(rows)
+}
+
+// GetSearchListings returns one summary per item the search has observed, ordered by item ID.
+// Observations are aggregated in the query so only a row per item is loaded.
+func (d *DB) GetSearchListings(ctx context.Context, searchID int) ([]SearchListing, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT latest.item_id, first.observed_at, latest.price, latest.currency_code, i.first_seen_at, i.sold_at, i.removed_at
+        FROM (
+            SELECT MIN(id) AS first_id, MAX(id) AS latest_id
+            FROM item_observations
+            WHERE search_id = ?
+            GROUP BY item_id
+        ) bounds
+        JOIN item_observations first ON first.id = bounds.first_id
+        JOIN item_observations latest ON latest.id = bounds.latest_id
+        JOIN items i ON i.id = latest.item_id
+        ORDER BY latest.item_id`, searchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	listings := make([]SearchListing, 0)
+	for rows.Next() {
+		var listing SearchListing
+		var price sql.NullFloat64
+		var soldAt, removedAt sql.NullTime
+
+		if err := rows.Scan(&listing.ItemID, &listing.FirstObservedAt, &price, &listing.CurrencyCode, &listing.FirstSeenAt, &soldAt, &removedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if price.Valid {
+			listing.Price = &price.Float64
+		}
+		if soldAt.Valid {
+			listing.SoldAt = &soldAt.Time
+		}
+		if removedAt.Valid {
+			listing.RemovedAt = &removedAt.Time
+		}
+
+		listings = append(listings, listing)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return listings, nil
+}
+
+// GetComparablePrices returns the latest observed price of every other item with the same brand
+// and size as the given item, priced in the same currency and observed since the given time.
+// Items without a brand have no comparables.
+func (d *DB) GetComparablePrices(ctx context.Context, item vinted.Item, since time.Time) ([]float64, error) {
+	if item.BrandTitle == "" {
+		return nil, nil
+	}
+
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT o.price
+        FROM item_observations o
+        JOIN items i ON i.id = o.item_id
+        WHERE i.brand = ? COLLATE NOCASE
+            AND i.size = ? COLLATE NOCASE
+            AND i.id != ?
+            AND o.id = (SELECT MAX(id) FROM item_observations WHERE item_id = o.item_id)
+            AND o.price IS NOT NULL
+            AND o.currency_code = ?
+            AND o.observed_at >= ?`,
+		item.BrandTitle, item.SizeTitle, item.ID, item.Price.CurrencyCode, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	prices := make([]float64, 0)
+	for rows.Next() {
+		var price float64
+		if err := rows.Scan(&price); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		prices = append(prices, price)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return prices, nil
+}
+
+func scanItemRecords(rows *sql.Rows) ([]ItemRecord, error) {
+	items := make([]ItemRecord, 0)
+	for rows.Next() {
+		var item ItemRecord
+		var checkedAt, soldAt, removedAt sql.NullTime
+
+		if err := rows.Scan(&item.ID, &item.Title, &item.Brand, &item.Size, &item.SellerID, &item.SellerLogin, &item.PhotoURL, &item.URL, &item.Status, &item.FirstSeenAt, &item.LastSeenAt, &checkedAt, &soldAt, &removedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if checkedAt.Valid {
+			item.AvailabilityCheckedAt = &checkedAt.Time
+		}
+		if soldAt.Valid {
+			item.SoldAt = &soldAt.Time
+		}
+		if removedAt.Valid {
+			item.RemovedAt = &removedAt.Time
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return items, nil
+}
+
+func scanObservations(rows *sql.Rows) ([]ItemObservation, error) {
+	observations := make([]ItemObservation, 0)
+	for rows.Next() {
+		var observation ItemObservation
+		var price, totalPrice sql.NullFloat64
+
+		if err := rows.Scan(&observation.ID, &observation.ItemID, &observation.SearchID, &price, &totalPrice, &observation.CurrencyCode, &observation.FavouriteCount, &observation.ViewCount, &observation.ObservedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if price.Valid {
+			observation.Price = &price.Float64
+		}
+		if totalPrice.Valid {
+			observation.TotalPrice = &totalPrice.Float64
+		}
+
+		observations = append(observations, observation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return observations, nil
+}
+
+// nullableAmount parses a Vinted amount, storing NULL when it is missing or malformed
+func nullableAmount(amount string) any {
+	value, err := vinted.ParseAmount(amount)
+	if err != nil {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RecordObservations(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "arcteryx beta"}))
+	require.NoError(t, err)
+
+	item := vinted.Item{
+		ID:             7,
+		Title:          "Arc'teryx Beta LT",
+		BrandTitle:     "Arc'teryx",
+		SizeTitle:      "M",
+		User:           vinted.User{ID: 42, Login: "anna"},
+		Photo:          vinted.ItemPhoto{URL: "https://images.vinted.net/7.jpg"},
+		URL:            "https://www.vinted.co.uk/items/7",
+		Status:         "Very good",
+		Price:          vinted.Price{Amount: "180.00", CurrencyCode: "GBP"},
+		TotalItemPrice: vinted.TotalItemPrice{Amount: "189.70", CurrencyCode: "GBP"},
+		FavouriteCount: 3,
+		ViewCount:      40,
+	}
+	require.NoError(t, db.RecordObservations(ctx, searchID, []vinted.Item{item}))
+
+	item.Title = "Arc'teryx Beta LT jacket"
+	item.Price = vinted.Price{Amount: "160.00", CurrencyCode: "GBP"}
+	item.TotalItemPrice = vinted.TotalItemPrice{}
+	item.FavouriteCount = 5
+	require.NoError(t, db.RecordObservations(ctx, searchID, []vinted.Item{item}))
+
+	record, err := db.GetItem(ctx, 7)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "Arc'teryx Beta LT jacket", record.Title)
+	assert.Equal(t, "Arc'teryx", record.Brand)
+	assert.Equal(t, "M", record.Size)
+	assert.Equal(t, 42, record.SellerID)
+	assert.Equal(t, "anna", record.SellerLogin)
+	assert.Equal(t, "https://images.vinted.net/7.jpg", record.PhotoURL)
+	assert.Equal(t, "https://www.vinted.co.uk/items/7", record.URL)
+	assert.Equal(t, "Very good", record.Status)
+	assert.False(t, record.LastSeenAt.Before(record.FirstSeenAt))
+
+	observations, err := db.GetItemObservations(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, observations, 2)
+	assert.Equal(t, searchID, observations[0].SearchID)
+	require.NotNil(t, observations[0].Price)
+	assert.Equal(t, 180.0, *observations[0].Price)
+	require.NotNil(t, observations[0].TotalPrice)
+	assert.Equal(t, 189.70, *observations[0].TotalPrice)
+	assert.Equal(t, "GBP", observations[0].CurrencyCode)
+	assert.Equal(t, 3, observations[0].FavouriteCount)
+	assert.Equal(t, 40, observations[0].ViewCount)
+	assert.Equal(t, 160.0, *observations[1].Price)
+	assert.Nil(t, observations[1].TotalPrice)
+	assert.Equal(t, 5, observations[1].FavouriteCount)
+
+	// History outlives the search that observed it
+	require.NoError(t, db.DeleteSearch(ctx, searchID))
+	observations, err = db.GetItemObservations(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, observations, 2)
+	assert.Zero(t, observations[0].SearchID)
+
+	missing, err := db.GetItem(ctx, 8)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func Test_IsItemObserved(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	firstID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "first"}))
+	require.NoError(t, err)
+	secondID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "second"}))
+	require.NoError(t, err)
+
+	require.NoError(t, db.RecordObservations(ctx, firstID, []vinted.Item{{ID: 1}}))
+
+	observed, err := db.IsItemObserved(ctx, firstID, 1)
+	require.NoError(t, err)
+	assert.True(t, observed)
+
+	observed, err = db.IsItemObserved(ctx, firstID, 2)
+	require.NoError(t, err)
+	assert.False(t, observed)
+
+	observed, err = db.IsItemObserved(ctx, secondID, 1)
+	require.NoError(t, err)
+	assert.False(t, observed, "observations are per search")
+}
+
+func Test_GetSearchListings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	firstID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "first"}))
+	require.NoError(t, err)
+	secondID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "second"}))
+	require.NoError(t, err)
+
+	reduced := vinted.Item{ID: 2, Price: vinted.Price{Amount: "50.00", CurrencyCode: "GBP"}}
+	require.NoError(t, db.RecordObservations(ctx, firstID, []vinted.Item{{ID: 1}, {ID: 2, Price: vinted.Price{Amount: "60.00", CurrencyCode: "GBP"}}}))
+	require.NoError(t, db.RecordObservations(ctx, firstID, []vinted.Item{reduced}))
+	require.NoError(t, db.RecordObservations(ctx, secondID, []vinted.Item{{ID: 2}}))
+	_, err = db.MarkItemSold(ctx, reduced, nil)
+	require.NoError(t, err)
+
+	listings, err := db.GetSearchListings(ctx, firstID)
+	require.NoError(t, err)
+	require.Len(t, listings, 2, "observations should be aggregated per item")
+
+	assert.Equal(t, int64(1), listings[0].ItemID)
+	assert.Nil(t, listings[0].Price)
+	assert.Nil(t, listings[0].SoldAt)
+
+	assert.Equal(t, int64(2), listings[1].ItemID)
+	require.NotNil(t, listings[1].Price)
+	assert.Equal(t, 50.0, *listings[1].Price, "the latest observed price should be used")
+	assert.Equal(t, "GBP", listings[1].CurrencyCode)
+	assert.False(t, listings[1].FirstObservedAt.IsZero())
+	assert.NotNil(t, listings[1].SoldAt)
+
+	listings, err = db.GetSearchListings(ctx, secondID)
+	require.NoError(t, err)
+	assert.Len(t, listings, 1)
+}
+
+func Test_GetComparablePrices(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	listing := func(id int64, brand, size, amount, currency string) vinted.Item {
+		return vinted.Item{ID: id, BrandTitle: brand, SizeTitle: size, Price: vinted.Price{Amount: amount, CurrencyCode: currency}}
+	}
+
+	require.NoError(t, db.RecordObservations(ctx, searchID, []vinted.Item{
+		listing(1, "Barbour", "M", "50.00", "GBP"),
+		listing(2, "barbour", "m", "70.00", "GBP"),
+		listing(3, "Barbour", "L", "60.00", "GBP"),
+		listing(4, "Barbour", "M", "55.00", "EUR"),
+		listing(5, "Hunter", "M", "20.00", "GBP"),
+	}))
+	// Only an item's latest price counts
+	require.NoError(t, db.RecordObservations(ctx, searchID, []vinted.Item{listing(2, "Barbour", "M", "65.00", "GBP")}))
+
+	prices, err := db.GetComparablePrices(ctx, listing(9, "Barbour", "M", "45.00", "GBP"), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []float64{50, 65}, prices)
+
+	// The item itself isn't a comparable
+	prices, err = db.GetComparablePrices(ctx, listing(1, "Barbour", "M", "45.00", "GBP"), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []float64{65}, prices)
+
+	prices, err = db.GetComparablePrices(ctx, listing(9, "Barbour", "M", "45.00", "GBP"), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, prices)
+
+	prices, err = db.GetComparablePrices(ctx, listing(9, "", "M", "45.00", "GBP"), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, prices)
+}
+
+func Test_ItemAvailabilityTracking(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	items := []vinted.Item{{ID: 1, Title: "Bedale"}, {ID: 2, Title: "Beaufort"}, {ID: 3, Title: "Liddesdale"}}
+	require.NoError(t, db.RecordObservations(ctx, searchID, items))
+
+	// Only items that were notified are tracked
+	_, err = db.RecordNewItems(ctx, searchID, nil, items[:2], nil)
+	require.NoError(t, err)
+
+	toRecheck, err := db.GetItemsToRecheck(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, toRecheck, 2)
+	assert.Equal(t, int64(1), toRecheck[0].ID)
+	assert.Equal(t, int64(2), toRecheck[1].ID)
+
+	searchIDs, err := db.GetItemSearchIDs(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{searchID}, searchIDs)
+
+	// Items still listed wait until they are due again
+	require.NoError(t, db.MarkItemAvailable(ctx, 1))
+	toRecheck, err = db.GetItemsToRecheck(ctx, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, toRecheck, 1)
+	assert.Equal(t, int64(2), toRecheck[0].ID)
+
+	target := domain.NotificationTarget{Type: domain.NotificationTargetDiscord, URL: "https://discord.example/hook"}
+	timeToSell, err := db.MarkItemSold(ctx, items[1], map[int][]domain.NotificationTarget{searchID: {target}})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, timeToSell, time.Duration(0))
+
+	record, err := db.GetItem(ctx, 2)
+	require.NoError(t, err)
+	require.NotNil(t, record.SoldAt)
+	assert.Nil(t, record.RemovedAt)
+	recordedTimeToSell, sold := record.TimeToSell()
+	assert.True(t, sold)
+	assert.Equal(t, record.SoldAt.Sub(record.FirstSeenAt), recordedTimeToSell)
+
+	due, err := db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, int64(2), due[0].Item.ID)
+	require.NotNil(t, due[0].TimeToSell)
+	assert.Equal(t, timeToSell.Truncate(time.Second), *due[0].TimeToSell)
+
+	// A sale is only announced once
+	_, err = db.MarkItemSold(ctx, items[1], map[int][]domain.NotificationTarget{searchID: {target}})
+	require.NoError(t, err)
+	due, err = db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	require.NoError(t, db.MarkItemRemoved(ctx, 1))
+	record, err = db.GetItem(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, record.RemovedAt)
+	_, sold = record.TimeToSell()
+	assert.False(t, sold)
+
+	// Sold and removed items are no longer re-checked
+	toRecheck, err = db.GetItemsToRecheck(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, toRecheck)
+}
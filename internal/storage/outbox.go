@@ -0,0 +1,411 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+)
+
+// ErrOutboxEntryNotFound is returned when an operation targets an outbox entry that does not exist
+var ErrOutboxEntryNotFound = errors.New("outbox entry not found")
+
+// OutboxEntry is a pending or dead-lettered notification of a single item to a single target
+type OutboxEntry struct {
+	ID       int
+	SearchID int
+	Target   domain.NotificationTarget
+	Item     vinted.Item
+	// PreviousPrice is set when the entry announces a price drop rather than a new item
+	PreviousPrice *vinted.Price
+	// DealScore is the percentile of the item's price among comparable listings, if known
+	DealScore *float64
+	// TimeToSell is set when the entry announces that a watched item has sold
+	TimeToSell    *time.Duration
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	DeadAt        *time.Time
+	CreatedAt     time.Time
+}
+
+const outboxColumns = "id, search_id, target, item, previous_price, deal_score, time_to_sell, attempts, next_attempt_at, last_error, dead_at, created_at"
+
+// RecordNewItems marks items as seen and, in the same transaction, queues a notification to
+// every target for each item that had not been seen before, along with its deal score if
+// known. It returns the newly seen items.
+func (d *DB) RecordNewItems(ctx context.Context, searchID int, targets []domain.NotificationTarget, items []vinted.Item, dealScores map[int64]float64) ([]vinted.Item, error) {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	targetsJSON, err := marshalOutboxTargets(targets)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	newItems := make([]vinted.Item, 0)
+	for _, item := range items {
+		lastPrice, err := marshalLastPrice(item)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := tx.ExecContext(ctx, `
+            INSERT OR IGNORE INTO seen_items (search_id, item_id, last_price)
+            VALUES (?, ?, ?)`, searchID, item.ID, lastPrice)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark item %d as seen: %w", item.ID, err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			continue
+		}
+
+		newItems = append(newItems, item)
+
+		var dealScore *float64
+		if score, ok := dealScores[item.ID]; ok {
+			dealScore = &score
+		}
+
+		if err := enqueueOutboxEntries(ctx, tx, searchID, targetsJSON, item, nil, dealScore, nil, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return newItems, nil
+}
+
+// PriceDrop is a reduction in the price of an item that had already been seen
+type PriceDrop struct {
+	Item          vinted.Item
+	PreviousPrice vinted.Price
+}
+
+// RecordPrices records the latest price of items that have already been seen and, in the same
+// transaction, queues a price drop notification to every target for each item whose price fell
+// by at least minDropPercent since it was last observed. Unseen items are ignored; they are
+// recorded by RecordNewItems.
+func (d *DB) RecordPrices(ctx context.Context, searchID int, targets []domain.NotificationTarget, items []vinted.Item, minDropPercent float64) ([]PriceDrop, error) {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	targetsJSON, err := marshalOutboxTargets(targets)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	drops := make([]PriceDrop, 0)
+	for _, item := range items {
+		var lastPriceJSON sql.NullString
+		err := tx.QueryRowContext(ctx, `
+            SELECT last_price
+            FROM seen_items
+            WHERE search_id = ? AND item_id = ?`, searchID, item.ID).Scan(&lastPriceJSON)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last price of item %d: %w", item.ID, err)
+		}
+
+		lastPrice, err := marshalLastPrice(item)
+		if err != nil {
+			return nil, err
+		}
+
+		if lastPrice == nil || (lastPriceJSON.Valid && lastPriceJSON.String == *lastPrice) {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE seen_items
+            SET last_price = ?
+            WHERE search_id = ? AND item_id = ?`, *lastPrice, searchID, item.ID); err != nil {
+			return nil, fmt.Errorf("failed to update last price of item %d: %w", item.ID, err)
+		}
+
+		if !lastPriceJSON.Valid {
+			continue
+		}
+
+		var previousPrice vinted.Price
+		if err := json.Unmarshal([]byte(lastPriceJSON.String), &previousPrice); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal last price of item %d: %w", item.ID, err)
+		}
+
+		if !isPriceDrop(previousPrice, item.Price, minDropPercent) {
+			continue
+		}
+
+		drops = append(drops, PriceDrop{Item: item, PreviousPrice: previousPrice})
+
+		if err := enqueueOutboxEntries(ctx, tx, searchID, targetsJSON, item, &previousPrice, nil, nil, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return drops, nil
+}
+
+// isPriceDrop reports whether the price fell by at least minDropPercent. Prices in different
+// currencies can't be compared.
+func isPriceDrop(previous, current vinted.Price, minDropPercent float64) bool {
+	if previous.CurrencyCode != current.CurrencyCode {
+		return false
+	}
+
+	previousAmount, err := vinted.ParseAmount(previous.Amount)
+	if err != nil || previousAmount <= 0 {
+		return false
+	}
+
+	currentAmount, err := vinted.ParseAmount(current.Amount)
+	if err != nil || currentAmount >= previousAmount {
+		return false
+	}
+
+	dropPercent := (previousAmount - currentAmount) / previousAmount * 100
+	return dropPercent >= minDropPercent
+}
+
+// marshalLastPrice returns the item's price as stored in seen_items, or nil if it has none
+func marshalLastPrice(item vinted.Item) (*string, error) {
+	if item.Price.Amount == "" {
+		return nil, nil
+	}
+
+	priceJSON, err := json.Marshal(item.Price)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal price of item %d: %w", item.ID, err)
+	}
+
+	lastPrice := string(priceJSON)
+	return &lastPrice, nil
+}
+
+func marshalOutboxTargets(targets []domain.NotificationTarget) ([]string, error) {
+	targetsJSON := make([]string, 0, len(targets))
+	for _, target := range targets {
+		targetJSON, err := json.Marshal(target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal notification target: %w", err)
+		}
+		targetsJSON = append(targetsJSON, string(targetJSON))
+	}
+	return targetsJSON, nil
+}
+
+// enqueueOutboxEntries queues a notification of the item to every target
+func enqueueOutboxEntries(ctx context.Context, tx *sql.Tx, searchID int, targetsJSON []string, item vinted.Item, previousPrice *vinted.Price, dealScore *float64, timeToSell *time.Duration, now time.Time) error {
+	if len(targetsJSON) == 0 {
+		return nil
+	}
+
+	itemJSON, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal item %d: %w", item.ID, err)
+	}
+
+	var previousPriceJSON *string
+	if previousPrice != nil {
+		priceJSON, err := json.Marshal(previousPrice)
+		if err != nil {
+			return fmt.Errorf("failed to marshal previous price of item %d: %w", item.ID, err)
+		}
+		value := string(priceJSON)
+		previousPriceJSON = &value
+	}
+
+	var timeToSellSeconds *int64
+	if timeToSell != nil {
+		seconds := int64(timeToSell.Seconds())
+		timeToSellSeconds = &seconds
+	}
+
+	for _, targetJSON := range targetsJSON {
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO notification_outbox (search_id, target, item, previous_price, deal_score, time_to_sell, next_attempt_at, created_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, searchID, targetJSON, string(itemJSON), previousPriceJSON, dealScore, timeToSellSeconds, now.Unix(), now)
+		if err != nil {
+			return fmt.Errorf("failed to queue notification for item %d: %w", item.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// GetDueOutboxEntries returns entries that are not dead-lettered and whose next attempt is due
+func (d *DB) GetDueOutboxEntries(ctx context.Context, now time.Time, limit int) ([]OutboxEntry, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT `+outboxColumns+`
+        FROM notification_outbox
+        WHERE dead_at IS NULL AND next_attempt_at <= ?
+        ORDER BY id
+        LIMIT ?`, now.Unix(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOutboxEntries(rows)
+}
+
+func (d *DB) GetDeadLetters(ctx context.Context) ([]OutboxEntry, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT `+outboxColumns+`
+        FROM notification_outbox
+        WHERE dead_at IS NOT NULL
+        ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOutboxEntries(rows)
+}
+
+func (d *DB) DeleteOutboxEntries(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	_, err := d.conn.ExecContext(ctx, `
+        DELETE FROM notification_outbox
+        WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	return nil
+}
+
+// RecordOutboxFailure counts a failed delivery attempt, scheduling the next attempt or
+// dead-lettering the entry
+func (d *DB) RecordOutboxFailure(ctx context.Context, id int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	var deadAt any
+	if dead {
+		deadAt = time.Now().UTC()
+	}
+
+	result, err := d.conn.ExecContext(ctx, `
+        UPDATE notification_outbox
+        SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?, dead_at = ?
+        WHERE id = ?`, lastError, nextAttemptAt.Unix(), deadAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	return requireOutboxRowsAffected(result)
+}
+
+// ReplayDeadLetter returns a dead-lettered entry to the queue with a fresh set of attempts
+func (d *DB) ReplayDeadLetter(ctx context.Context, id int) error {
+	result, err := d.conn.ExecContext(ctx, `
+        UPDATE notification_outbox
+        SET attempts = 0, next_attempt_at = ?, dead_at = NULL
+        WHERE id = ? AND dead_at IS NOT NULL`, time.Now().Unix(), id)
+	if err != nil {
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	return requireOutboxRowsAffected(result)
+}
+
+func scanOutboxEntries(rows *sql.Rows) ([]OutboxEntry, error) {
+	entries := make([]OutboxEntry, 0)
+	for rows.Next() {
+		var entry OutboxEntry
+		var targetJSON, itemJSON string
+		var previousPriceJSON sql.NullString
+		var dealScore sql.NullFloat64
+		var timeToSell sql.NullInt64
+		var nextAttemptAt int64
+		var deadAt sql.NullTime
+
+		if err := rows.Scan(&entry.ID, &entry.SearchID, &targetJSON, &itemJSON, &previousPriceJSON, &dealScore, &timeToSell, &entry.Attempts, &nextAttemptAt, &entry.LastError, &deadAt, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		entry.NextAttemptAt = time.Unix(nextAttemptAt, 0).UTC()
+		if deadAt.Valid {
+			entry.DeadAt = &deadAt.Time
+		}
+
+		if err := json.Unmarshal([]byte(targetJSON), &entry.Target); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal notification target: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(itemJSON), &entry.Item); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+		}
+
+		if previousPriceJSON.Valid {
+			if err := json.Unmarshal([]byte(previousPriceJSON.String), &entry.PreviousPrice); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal previous price: %w", err)
+			}
+		}
+
+		if dealScore.Valid {
+			entry.DealScore = &dealScore.Float64
+		}
+
+		if timeToSell.Valid {
+			duration := time.Duration(timeToSell.Int64) * time.Second
+			entry.TimeToSell = &duration
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+func requireOutboxRowsAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrOutboxEntryNotFound
+	}
+
+	return nil
+}
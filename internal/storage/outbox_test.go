@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RecordNewItems_QueuesOnlyUnseenItems(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	targets := []domain.NotificationTarget{
+		{Type: domain.NotificationTargetDiscord, URL: "https://discord.example/hook"},
+		{Type: domain.NotificationTargetSlack, URL: "https://slack.example/hook"},
+	}
+	items := []vinted.Item{{ID: 1, Title: "Bedale"}, {ID: 2, Title: "Beaufort"}}
+
+	newItems, err := db.RecordNewItems(ctx, searchID, targets, items, map[int64]float64{2: 12.5})
+	require.NoError(t, err)
+	assert.Equal(t, items, newItems)
+
+	seen, err := db.IsItemSeen(ctx, searchID, 1)
+	require.NoError(t, err)
+	assert.True(t, seen)
+
+	// One entry per item per target
+	due, err := db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 4)
+	assert.Equal(t, searchID, due[0].SearchID)
+	assert.Equal(t, targets[0], due[0].Target)
+	assert.Equal(t, items[0], due[0].Item)
+	assert.Zero(t, due[0].Attempts)
+	assert.Nil(t, due[0].DealScore)
+	require.NotNil(t, due[2].DealScore)
+	assert.Equal(t, 12.5, *due[2].DealScore)
+
+	newItems, err = db.RecordNewItems(ctx, searchID, targets, append(items, vinted.Item{ID: 3}), nil)
+	require.NoError(t, err)
+	require.Len(t, newItems, 1)
+	assert.Equal(t, int64(3), newItems[0].ID)
+
+	due, err = db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	assert.Len(t, due, 6)
+
+	// Deleting the search drops its queued notifications
+	require.NoError(t, db.DeleteSearch(ctx, searchID))
+	due, err = db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+func Test_OutboxFailureDeadLetterAndReplay(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	target := domain.NotificationTarget{Type: domain.NotificationTargetDiscord, URL: "https://discord.example/hook"}
+	_, err = db.RecordNewItems(ctx, searchID, []domain.NotificationTarget{target}, []vinted.Item{{ID: 1}}, nil)
+	require.NoError(t, err)
+
+	due, err := db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	id := due[0].ID
+
+	// A retry scheduled in the future is not due yet
+	nextAttempt := time.Now().Add(time.Minute)
+	require.NoError(t, db.RecordOutboxFailure(ctx, id, "boom", nextAttempt, false))
+
+	due, err = db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = db.GetDueOutboxEntries(ctx, nextAttempt, 100)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, "boom", due[0].LastError)
+
+	// Only dead letters can be replayed
+	assert.ErrorIs(t, db.ReplayDeadLetter(ctx, id), ErrOutboxEntryNotFound)
+
+	require.NoError(t, db.RecordOutboxFailure(ctx, id, "boom again", nextAttempt, true))
+
+	due, err = db.GetDueOutboxEntries(ctx, nextAttempt.Add(time.Hour), 100)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	dead, err := db.GetDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 2, dead[0].Attempts)
+	assert.Equal(t, "boom again", dead[0].LastError)
+	require.NotNil(t, dead[0].DeadAt)
+
+	require.NoError(t, db.ReplayDeadLetter(ctx, id))
+
+	dead, err = db.GetDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+
+	due, err = db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Zero(t, due[0].Attempts)
+
+	require.NoError(t, db.DeleteOutboxEntries(ctx, []int{id}))
+	assert.ErrorIs(t, db.RecordOutboxFailure(ctx, id, "gone", time.Now(), false), ErrOutboxEntryNotFound)
+}
+
+func Test_RecordPrices_QueuesPriceDrops(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	searchID, err := db.CreateSearch(ctx, domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"}))
+	require.NoError(t, err)
+
+	target := domain.NotificationTarget{Type: domain.NotificationTargetDiscord, URL: "https://discord.example/hook"}
+	targets := []domain.NotificationTarget{target}
+
+	pricedItem := func(id int64, amount string) vinted.Item {
+		return vinted.Item{ID: id, Price: vinted.Price{Amount: amount, CurrencyCode: "GBP"}}
+	}
+
+	_, err = db.RecordNewItems(ctx, searchID, targets, []vinted.Item{pricedItem(1, "50.00"), pricedItem(2, "20.00")}, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.DeleteOutboxEntries(ctx, outboxIDs(t, db)))
+
+	// Unseen items are left to RecordNewItems
+	drops, err := db.RecordPrices(ctx, searchID, targets, []vinted.Item{pricedItem(1, "40.00"), pricedItem(2, "19.50"), pricedItem(3, "5.00")}, 10)
+	require.NoError(t, err)
+	require.Len(t, drops, 1)
+	assert.Equal(t, int64(1), drops[0].Item.ID)
+	assert.Equal(t, vinted.Price{Amount: "50.00", CurrencyCode: "GBP"}, drops[0].PreviousPrice)
+
+	due, err := db.GetDueOutboxEntries(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, int64(1), due[0].Item.ID)
+	assert.Equal(t, &vinted.Price{Amount: "50.00", CurrencyCode: "GBP"}, due[0].PreviousPrice)
+
+	seen, err := db.IsItemSeen(ctx, searchID, 3)
+	require.NoError(t, err)
+	assert.False(t, seen)
+
+	// Prices are compared with the last observed price, so unchanged and raised prices don't alert
+	drops, err = db.RecordPrices(ctx, searchID, targets, []vinted.Item{pricedItem(1, "40.00"), pricedItem(2, "25.00")}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, drops)
+
+	drops, err = db.RecordPrices(ctx, searchID, targets, []vinted.Item{pricedItem(2, "22.00")}, 10)
+	require.NoError(t, err)
+	require.Len(t, drops, 1)
+	assert.Equal(t, "25.00", drops[0].PreviousPrice.Amount)
+}
+
+func outboxIDs(t *testing.T, db *DB) []int {
+	t.Helper()
+
+	due, err := db.GetDueOutboxEntries(context.Background(), time.Now(), 100)
+	require.NoError(t, err)
+
+	ids := make([]int, 0, len(due))
+	for _, entry := range due {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
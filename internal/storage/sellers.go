@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrSellerNotBlocked is returned when unblocking a seller that is not on the blocklist
+var ErrSellerNotBlocked = errors.New("seller not blocked")
+
+// BlockedSeller is a seller, identified by Vinted user ID or login, whose items are never
+// notified for any search
+type BlockedSeller struct {
+	Seller    string
+	Reason    string
+	CreatedAt time.Time
+}
+
+// BlockSeller adds the seller to the global blocklist, updating the reason if already blocked
+func (d *DB) BlockSeller(ctx context.Context, seller string, reason string) error {
+	_, err := d.conn.ExecContext(ctx, `
+        INSERT INTO blocked_sellers (seller, reason, created_at)
+        VALUES (?, ?, ?)
+        ON CONFLICT (seller) DO UPDATE SET reason = excluded.reason`, normaliseSeller(seller), reason, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DB) UnblockSeller(ctx context.Context, seller string) error {
+	result, err := d.conn.ExecContext(ctx, `
+        DELETE FROM blocked_sellers
+        WHERE seller = ?`, normaliseSeller(seller))
+	if err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrSellerNotBlocked
+	}
+
+	return nil
+}
+
+func (d *DB) GetBlockedSellers(ctx context.Context) ([]BlockedSeller, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT seller, reason, created_at
+        FROM blocked_sellers
+        ORDER BY seller`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	sellers := make([]BlockedSeller, 0)
+	for rows.Next() {
+		var seller BlockedSeller
+		if err := rows.Scan(&seller.Seller, &seller.Reason, &seller.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		sellers = append(sellers, seller)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return sellers, nil
+}
+
+// normaliseSeller lowercases logins so blocking is case-insensitive, as Vinted logins are
+func normaliseSeller(seller string) string {
+	return strings.ToLower(strings.TrimSpace(seller))
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BlockedSellers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	require.NoError(t, db.BlockSeller(ctx, "ResellerUK", "flips everything"))
+	require.NoError(t, db.BlockSeller(ctx, "12345", ""))
+
+	// Blocking again updates the reason rather than failing
+	require.NoError(t, db.BlockSeller(ctx, "reselleruk", "known reseller"))
+
+	sellers, err := db.GetBlockedSellers(ctx)
+	require.NoError(t, err)
+	require.Len(t, sellers, 2)
+	assert.Equal(t, "12345", sellers[0].Seller)
+	assert.Equal(t, "reselleruk", sellers[1].Seller)
+	assert.Equal(t, "known reseller", sellers[1].Reason)
+	assert.False(t, sellers[1].CreatedAt.IsZero())
+
+	require.NoError(t, db.UnblockSeller(ctx, "RESELLERUK"))
+	assert.ErrorIs(t, db.UnblockSeller(ctx, "reselleruk"), ErrSellerNotBlocked)
+
+	sellers, err = db.GetBlockedSellers(ctx)
+	require.NoError(t, err)
+	require.Len(t, sellers, 1)
+	assert.Equal(t, "12345", sellers[0].Seller)
+}
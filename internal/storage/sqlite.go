@@ -1,9 +1,12 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 	"vinted-watcher/internal/domain"
 
@@ -12,16 +15,25 @@ import (
 
 var now = time.Now()
 
+const searchColumns = "id, name, search_params, interval_seconds, notification_targets, item_filters, price_drop_alerts, last_checked, active, created_at, updated_at"
+
+// ErrSearchNotFound is returned when an operation targets a search that does not exist
+var ErrSearchNotFound = errors.New("search not found")
+
 type DB struct {
 	conn *sql.DB
 }
 
 func NewDB(dbPath string) (*DB, error) {
-	conn, err := sql.Open("sqlite3", dbPath)
+	conn, err := sql.Open("sqlite3", withForeignKeys(dbPath))
 	if err != nil {
 		return nil, err
 	}
 
+	// SQLite only supports a single writer; serialising access through one connection avoids
+	// "database is locked" errors when searches are processed concurrently
+	conn.SetMaxOpenConns(1)
+
 	db := &DB{conn: conn}
 
 	// Ensure the database is created and ready
@@ -36,17 +48,32 @@ func NewDB(dbPath string) (*DB, error) {
 	return db, nil
 }
 
-func (d *DB) CreateSearch(search *domain.SavedSearch) (int, error) {
+func (d *DB) CreateSearch(ctx context.Context, search *domain.SavedSearch) (int, error) {
 
 	searchParamsJSON, err := json.Marshal(*search.SearchParams)
 	if err != nil {
 		return 0, fmt.Errorf("failed to marshal search params: %w", err)
 	}
 
-	result, err := d.conn.Exec(`
-        INSERT INTO saved_searches (name, search_params, last_checked, active, created_at, updated_at)
-        VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
-		search.Name, searchParamsJSON, search.LastChecked, search.Active)
+	notificationTargetsJSON, err := marshalNotificationTargets(search.NotificationTargets)
+	if err != nil {
+		return 0, err
+	}
+
+	itemFiltersJSON, err := json.Marshal(search.Filters)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal item filters: %w", err)
+	}
+
+	priceDropAlertsJSON, err := json.Marshal(search.PriceDropAlerts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal price drop alerts: %w", err)
+	}
+
+	result, err := d.conn.ExecContext(ctx, `
+        INSERT INTO saved_searches (name, search_params, interval_seconds, notification_targets, item_filters, price_drop_alerts, last_checked, active, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		search.Name, searchParamsJSON, intervalSeconds(search.Interval), notificationTargetsJSON, itemFiltersJSON, priceDropAlertsJSON, search.LastChecked, search.Active)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to execute insert query: %w", err)
@@ -60,32 +87,26 @@ func (d *DB) CreateSearch(search *domain.SavedSearch) (int, error) {
 	return int(searchID), nil
 }
 
-func (d *DB) GetSearchByID(id int) (*domain.SavedSearch, error) {
-	var search domain.SavedSearch
-	var searchParamsJSON string
-
-	err := d.conn.QueryRow(`
-        SELECT *
+func (d *DB) GetSearchByID(ctx context.Context, id int) (*domain.SavedSearch, error) {
+	row := d.conn.QueryRowContext(ctx, `
+        SELECT `+searchColumns+`
         FROM saved_searches
-        WHERE id = ?`, id).Scan(&search.ID, &search.Name, &searchParamsJSON, &search.LastChecked, &search.Active, &search.CreatedAt, &search.UpdatedAt)
+        WHERE id = ?`, id)
 
+	search, err := scanSearch(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to execute select query: %w", err)
-	}
-
-	if err := json.Unmarshal([]byte(searchParamsJSON), &search.SearchParams); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal search params: %w", err)
+		return nil, err
 	}
 
-	return &search, nil
+	return search, nil
 }
 
-func (d *DB) GetAllSearches() ([]*domain.SavedSearch, error) {
-	rows, err := d.conn.Query(`
-        SELECT *
+func (d *DB) GetAllSearches(ctx context.Context) ([]*domain.SavedSearch, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+        SELECT `+searchColumns+`
         FROM saved_searches`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute select query: %w", err)
@@ -94,18 +115,12 @@ func (d *DB) GetAllSearches() ([]*domain.SavedSearch, error) {
 
 	var searches []*domain.SavedSearch
 	for rows.Next() {
-		var search domain.SavedSearch
-		var searchParamsJSON string
-
-		if err := rows.Scan(&search.ID, &search.Name, &searchParamsJSON, &search.LastChecked, &search.Active, &search.CreatedAt, &search.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+		search, err := scanSearch(rows)
+		if err != nil {
+			return nil, err
 		}
 
-		if err := json.Unmarshal([]byte(searchParamsJSON), &search.SearchParams); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal search params: %w", err)
-		}
-
-		searches = append(searches, &search)
+		searches = append(searches, search)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -115,9 +130,77 @@ func (d *DB) GetAllSearches() ([]*domain.SavedSearch, error) {
 	return searches, nil
 }
 
-func (d *DB) IsItemSeen(searchID int, itemID int) (bool, error) {
+func (d *DB) UpdateSearch(ctx context.Context, search *domain.SavedSearch) error {
+	searchParamsJSON, err := json.Marshal(*search.SearchParams)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search params: %w", err)
+	}
+
+	notificationTargetsJSON, err := marshalNotificationTargets(search.NotificationTargets)
+	if err != nil {
+		return err
+	}
+
+	itemFiltersJSON, err := json.Marshal(search.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal item filters: %w", err)
+	}
+
+	priceDropAlertsJSON, err := json.Marshal(search.PriceDropAlerts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal price drop alerts: %w", err)
+	}
+
+	result, err := d.conn.ExecContext(ctx, `
+        UPDATE saved_searches
+        SET name = ?, search_params = ?, interval_seconds = ?, notification_targets = ?, item_filters = ?, price_drop_alerts = ?, active = ?, updated_at = CURRENT_TIMESTAMP
+        WHERE id = ?`,
+		search.Name, searchParamsJSON, intervalSeconds(search.Interval), notificationTargetsJSON, itemFiltersJSON, priceDropAlertsJSON, search.Active, search.ID)
+	if err != nil {
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+func (d *DB) DeleteSearch(ctx context.Context, id int) error {
+	result, err := d.conn.ExecContext(ctx, `
+        DELETE FROM saved_searches
+        WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+func (d *DB) SetSearchActive(ctx context.Context, searchID int, active bool) error {
+	result, err := d.conn.ExecContext(ctx, `
+        UPDATE saved_searches
+        SET active = ?, updated_at = CURRENT_TIMESTAMP
+        WHERE id = ?`, active, searchID)
+	if err != nil {
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+func (d *DB) UpdateLastChecked(ctx context.Context, searchID int) error {
+	result, err := d.conn.ExecContext(ctx, `
+        UPDATE saved_searches
+        SET last_checked = ?
+        WHERE id = ?`, time.Now().UTC(), searchID)
+	if err != nil {
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+func (d *DB) IsItemSeen(ctx context.Context, searchID int, itemID int) (bool, error) {
 	var seen bool
-	err := d.conn.QueryRow(`
+	err := d.conn.QueryRowContext(ctx, `
         SELECT EXISTS(
             SELECT 1
             FROM seen_items
@@ -131,8 +214,8 @@ func (d *DB) IsItemSeen(searchID int, itemID int) (bool, error) {
 	return seen, nil
 }
 
-func (d *DB) MarkItemAsSeen(searchID int, itemID int) error {
-	_, err := d.conn.Exec(`
+func (d *DB) MarkItemAsSeen(ctx context.Context, searchID int, itemID int) error {
+	_, err := d.conn.ExecContext(ctx, `
         INSERT INTO seen_items (search_id, item_id)
         VALUES (?, ?)`, searchID, itemID)
 
@@ -146,6 +229,88 @@ func (d *DB) Close() error {
 	return nil
 }
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSearch(row rowScanner) (*domain.SavedSearch, error) {
+	var search domain.SavedSearch
+	var searchParamsJSON string
+	var notificationTargetsJSON string
+	var itemFiltersJSON string
+	var priceDropAlertsJSON string
+	var interval int64
+
+	if err := row.Scan(&search.ID, &search.Name, &searchParamsJSON, &interval, &notificationTargetsJSON, &itemFiltersJSON, &priceDropAlertsJSON, &search.LastChecked, &search.Active, &search.CreatedAt, &search.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	search.Interval = time.Duration(interval) * time.Second
+
+	if err := json.Unmarshal([]byte(searchParamsJSON), &search.SearchParams); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal search params: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(notificationTargetsJSON), &search.NotificationTargets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal notification targets: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(itemFiltersJSON), &search.Filters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal item filters: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(priceDropAlertsJSON), &search.PriceDropAlerts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal price drop alerts: %w", err)
+	}
+
+	return &search, nil
+}
+
+func marshalNotificationTargets(targets []domain.NotificationTarget) ([]byte, error) {
+	if targets == nil {
+		targets = []domain.NotificationTarget{}
+	}
+
+	targetsJSON, err := json.Marshal(targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification targets: %w", err)
+	}
+	return targetsJSON, nil
+}
+
+func intervalSeconds(interval time.Duration) int64 {
+	if interval <= 0 {
+		interval = domain.DefaultInterval
+	}
+	return int64(interval / time.Second)
+}
+
+// withForeignKeys enables SQLite foreign key enforcement on every connection so that
+// deleting a search cascades to its seen items
+func withForeignKeys(dbPath string) string {
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	return dbPath + separator + "_foreign_keys=on"
+}
+
+func requireRowsAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrSearchNotFound
+	}
+
+	return nil
+}
+
 func (db *DB) createTables() error {
 	createSearchesTable := `
     CREATE TABLE IF NOT EXISTS saved_searches (
@@ -155,7 +320,11 @@ func (db *DB) createTables() error {
         last_checked DATETIME,
         active BOOLEAN DEFAULT 1,
         created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        interval_seconds INTEGER NOT NULL DEFAULT 3600,
+        notification_targets TEXT NOT NULL DEFAULT '[]',
+        item_filters TEXT NOT NULL DEFAULT '{}',
+        price_drop_alerts TEXT NOT NULL DEFAULT '{}'
     );`
 
 	createSeenItemsTable := `
@@ -163,16 +332,145 @@ func (db *DB) createTables() error {
         search_id INTEGER NOT NULL,
         item_id INTEGER NOT NULL,
         seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        last_price TEXT,
         PRIMARY KEY (search_id, item_id),
         FOREIGN KEY (search_id) REFERENCES saved_searches(id) ON DELETE CASCADE
     );`
 
-	if _, err := db.conn.Exec(createSearchesTable); err != nil {
-		return err
+	createPendingDigestItemsTable := `
+    CREATE TABLE IF NOT EXISTS pending_digest_items (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        recipients TEXT NOT NULL,
+        period_seconds INTEGER NOT NULL,
+        search_id INTEGER NOT NULL,
+        search_name TEXT NOT NULL,
+        item TEXT NOT NULL,
+        previous_price TEXT,
+        deal_score REAL,
+        queued_at DATETIME NOT NULL,
+        FOREIGN KEY (search_id) REFERENCES saved_searches(id) ON DELETE CASCADE
+    );`
+
+	createNotificationOutboxTable := `
+    CREATE TABLE IF NOT EXISTS notification_outbox (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        search_id INTEGER NOT NULL,
+        target TEXT NOT NULL,
+        item TEXT NOT NULL,
+        previous_price TEXT,
+        deal_score REAL,
+        time_to_sell INTEGER,
+        attempts INTEGER NOT NULL DEFAULT 0,
+        next_attempt_at INTEGER NOT NULL,
+        last_error TEXT NOT NULL DEFAULT '',
+        dead_at DATETIME,
+        created_at DATETIME NOT NULL,
+        FOREIGN KEY (search_id) REFERENCES saved_searches(id) ON DELETE CASCADE
+    );`
+
+	createBlockedSellersTable := `
+    CREATE TABLE IF NOT EXISTS blocked_sellers (
+        seller TEXT PRIMARY KEY,
+        reason TEXT NOT NULL DEFAULT '',
+        created_at DATETIME NOT NULL
+    );`
+
+	createItemsTable := `
+    CREATE TABLE IF NOT EXISTS items (
+        id INTEGER PRIMARY KEY,
+        title TEXT NOT NULL,
+        brand TEXT NOT NULL DEFAULT '',
+        size TEXT NOT NULL DEFAULT '',
+        seller_id INTEGER NOT NULL DEFAULT 0,
+        seller_login TEXT NOT NULL DEFAULT '',
+        photo_url TEXT NOT NULL DEFAULT '',
+        url TEXT NOT NULL DEFAULT '',
+        status TEXT NOT NULL DEFAULT '',
+        first_seen_at DATETIME NOT NULL,
+        last_seen_at DATETIME NOT NULL,
+        availability_checked_at DATETIME,
+        sold_at DATETIME,
+        removed_at DATETIME
+    );`
+
+	// Observations outlive the searches that made them so item history is kept
+	createItemObservationsTable := `
+    CREATE TABLE IF NOT EXISTS item_observations (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        item_id INTEGER NOT NULL,
+        search_id INTEGER,
+        price REAL,
+        total_price REAL,
+        currency_code TEXT NOT NULL DEFAULT '',
+        favourite_count INTEGER NOT NULL DEFAULT 0,
+        view_count INTEGER NOT NULL DEFAULT 0,
+        observed_at DATETIME NOT NULL,
+        FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+        FOREIGN KEY (search_id) REFERENCES saved_searches(id) ON DELETE SET NULL
+    );`
+
+	createItemObservationsIndexes := `
+    CREATE INDEX IF NOT EXISTS idx_item_observations_item_id ON item_observations (item_id, observed_at);
+    CREATE INDEX IF NOT EXISTS idx_item_observations_search_id ON item_observations (search_id, observed_at);`
+
+	for _, createTable := range []string{createSearchesTable, createSeenItemsTable, createPendingDigestItemsTable, createNotificationOutboxTable, createBlockedSellersTable, createItemsTable, createItemObservationsTable, createItemObservationsIndexes} {
+		if _, err := db.conn.Exec(createTable); err != nil {
+			return err
+		}
 	}
 
-	if _, err := db.conn.Exec(createSeenItemsTable); err != nil {
-		return err
+	return db.migrate()
+}
+
+// addedColumns lists columns introduced after a table was first created, so that databases
+// created by earlier versions can be brought up to date
+var addedColumns = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"saved_searches", "interval_seconds", "INTEGER NOT NULL DEFAULT 3600"},
+	{"saved_searches", "notification_targets", "TEXT NOT NULL DEFAULT '[]'"},
+	{"saved_searches", "item_filters", "TEXT NOT NULL DEFAULT '{}'"},
+	{"saved_searches", "price_drop_alerts", "TEXT NOT NULL DEFAULT '{}'"},
+	{"seen_items", "last_price", "TEXT"},
+	{"notification_outbox", "previous_price", "TEXT"},
+	{"pending_digest_items", "previous_price", "TEXT"},
+	{"notification_outbox", "deal_score", "REAL"},
+	{"items", "availability_checked_at", "DATETIME"},
+	{"items", "sold_at", "DATETIME"},
+	{"items", "removed_at", "DATETIME"},
+	{"notification_outbox", "time_to_sell", "INTEGER"},
+	{"pending_digest_items", "deal_score", "REAL"},
+}
+
+func (db *DB) migrate() error {
+	for _, c := range addedColumns {
+		if err := db.addColumnIfNotExists(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) addColumnIfNotExists(table, column, definition string) error {
+	var exists bool
+	err := db.conn.QueryRow(`
+        SELECT EXISTS(
+            SELECT 1
+            FROM pragma_table_info(?)
+            WHERE name = ?
+        )`, table, column).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	if _, err := db.conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
 	}
 
 	return nil
@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"context"
+	"database/sql"
+	"path/filepath"
 	"testing"
 	"time"
 	"vinted-watcher/internal/domain"
@@ -23,7 +26,7 @@ func Test_CreateAndGetSearch(t *testing.T) {
 	before := time.Now().UTC().Truncate(time.Second)
 
 	// Attempt to create the search in the database
-	id, err := db.CreateSearch(savedSearch)
+	id, err := db.CreateSearch(context.Background(), savedSearch)
 
 	after := time.Now().UTC().Truncate(time.Second)
 
@@ -33,7 +36,7 @@ func Test_CreateAndGetSearch(t *testing.T) {
 
 	assert.Equal(t, 1, id)
 
-	search, err := db.GetSearchByID(id)
+	search, err := db.GetSearchByID(context.Background(), id)
 	require.NoError(t, err)
 
 	assert.NotNil(t, search)
@@ -71,11 +74,11 @@ func Test_GetAllSearches(t *testing.T) {
 	}
 
 	for _, search := range expectedSearches {
-		_, err := db.CreateSearch(search)
+		_, err := db.CreateSearch(context.Background(), search)
 		require.NoError(t, err)
 	}
 
-	actualSearches, err := db.GetAllSearches()
+	actualSearches, err := db.GetAllSearches(context.Background())
 	require.NoError(t, err)
 
 	assert.Len(t, actualSearches, 2)
@@ -103,19 +106,238 @@ func Test_MarkItemAsSeenAndIsItemSeen(t *testing.T) {
 	}
 	savedSearch := domain.NewSavedSearch(searchParams)
 
-	searchID, err := db.CreateSearch(savedSearch)
+	searchID, err := db.CreateSearch(context.Background(), savedSearch)
 	require.NoError(t, err)
 
 	itemID := 12345
-	err = db.MarkItemAsSeen(searchID, itemID)
+	err = db.MarkItemAsSeen(context.Background(), searchID, itemID)
 	require.NoError(t, err)
 
-	isSeen, err := db.IsItemSeen(searchID, itemID)
+	isSeen, err := db.IsItemSeen(context.Background(), searchID, itemID)
 	require.NoError(t, err)
 	assert.True(t, isSeen)
 
 	// Check for a different item ID to ensure it's not seen
-	isSeen, err = db.IsItemSeen(searchID, 1234)
+	isSeen, err = db.IsItemSeen(context.Background(), searchID, 1234)
 	require.NoError(t, err)
 	assert.False(t, isSeen)
 }
+
+func Test_UpdateSearch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	searchID, err := db.CreateSearch(context.Background(), domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbur"}))
+	require.NoError(t, err)
+
+	search, err := db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+
+	search.Name = "barbour"
+	search.SearchParams = &domain.SearchParams{SearchText: "barbour", BrandIDs: []int{123}}
+	search.Active = false
+
+	err = db.UpdateSearch(context.Background(), search)
+	require.NoError(t, err)
+
+	updated, err := db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.Equal(t, "barbour", updated.Name)
+	assert.Equal(t, search.SearchParams, updated.SearchParams)
+	assert.False(t, updated.Active)
+}
+
+func Test_UpdateSearch_NotFound(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	search := domain.NewSavedSearch(&domain.SearchParams{SearchText: "test search"})
+	search.ID = 42
+
+	err := db.UpdateSearch(context.Background(), search)
+	assert.ErrorIs(t, err, ErrSearchNotFound)
+}
+
+func Test_SetSearchActive(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	searchID, err := db.CreateSearch(context.Background(), domain.NewSavedSearch(&domain.SearchParams{SearchText: "test search"}))
+	require.NoError(t, err)
+
+	err = db.SetSearchActive(context.Background(), searchID, false)
+	require.NoError(t, err)
+
+	search, err := db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.False(t, search.Active)
+
+	err = db.SetSearchActive(context.Background(), searchID, true)
+	require.NoError(t, err)
+
+	search, err = db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.True(t, search.Active)
+
+	assert.ErrorIs(t, db.SetSearchActive(context.Background(), 42, true), ErrSearchNotFound)
+}
+
+func Test_DeleteSearch_CascadesToSeenItems(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	searchID, err := db.CreateSearch(context.Background(), domain.NewSavedSearch(&domain.SearchParams{SearchText: "test search"}))
+	require.NoError(t, err)
+
+	require.NoError(t, db.MarkItemAsSeen(context.Background(), searchID, 12345))
+
+	err = db.DeleteSearch(context.Background(), searchID)
+	require.NoError(t, err)
+
+	search, err := db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.Nil(t, search)
+
+	var count int
+	err = db.conn.QueryRow(`SELECT COUNT(*) FROM seen_items WHERE search_id = ?`, searchID).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	assert.ErrorIs(t, db.DeleteSearch(context.Background(), searchID), ErrSearchNotFound)
+}
+
+func Test_IntervalIsPersisted(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	savedSearch := domain.NewSavedSearch(&domain.SearchParams{SearchText: "test search"})
+	savedSearch.Interval = 2 * time.Minute
+
+	searchID, err := db.CreateSearch(context.Background(), savedSearch)
+	require.NoError(t, err)
+
+	search, err := db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, search.Interval)
+
+	search.Interval = 6 * time.Hour
+	require.NoError(t, db.UpdateSearch(context.Background(), search))
+
+	search, err = db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, search.Interval)
+}
+
+func Test_UpdateLastChecked(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	searchID, err := db.CreateSearch(context.Background(), domain.NewSavedSearch(&domain.SearchParams{SearchText: "test search"}))
+	require.NoError(t, err)
+
+	before := time.Now().UTC().Add(-time.Second)
+	require.NoError(t, db.UpdateLastChecked(context.Background(), searchID))
+	after := time.Now().UTC().Add(time.Second)
+
+	search, err := db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.WithinRange(t, search.LastChecked, before, after)
+
+	assert.ErrorIs(t, db.UpdateLastChecked(context.Background(), 42), ErrSearchNotFound)
+}
+
+func Test_NewDB_MigratesLegacySchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = legacy.Exec(`
+    CREATE TABLE saved_searches (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL,
+        search_params TEXT NOT NULL,
+        last_checked DATETIME,
+        active BOOLEAN DEFAULT 1,
+        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    );
+    INSERT INTO saved_searches (name, search_params, last_checked, active)
+    VALUES ('legacy', '{"SearchText":"legacy"}', CURRENT_TIMESTAMP, 1);`)
+	require.NoError(t, err)
+	require.NoError(t, legacy.Close())
+
+	db, err := NewDB(dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	search, err := db.GetSearchByID(context.Background(), 1)
+	require.NoError(t, err)
+	require.NotNil(t, search)
+	assert.Equal(t, "legacy", search.Name)
+	assert.Equal(t, domain.DefaultInterval, search.Interval)
+}
+
+func Test_NotificationTargetsArePersisted(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	savedSearch := domain.NewSavedSearch(&domain.SearchParams{SearchText: "kids clothes"})
+	savedSearch.NotificationTargets = []domain.NotificationTarget{
+		{Type: domain.NotificationTargetDiscord, URL: "https://discord.example/kids"},
+	}
+
+	searchID, err := db.CreateSearch(context.Background(), savedSearch)
+	require.NoError(t, err)
+
+	search, err := db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.Equal(t, savedSearch.NotificationTargets, search.NotificationTargets)
+
+	search.NotificationTargets = nil
+	require.NoError(t, db.UpdateSearch(context.Background(), search))
+
+	search, err = db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.Empty(t, search.NotificationTargets)
+}
+
+func Test_ItemFiltersArePersisted(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	savedSearch := domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"})
+	savedSearch.Filters = domain.ItemFilters{
+		IncludeWords: []string{"wax"},
+		ExcludeWords: []string{"style"},
+		BrandPattern: "(?i)^barbour$",
+	}
+
+	searchID, err := db.CreateSearch(context.Background(), savedSearch)
+	require.NoError(t, err)
+
+	search, err := db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.Equal(t, savedSearch.Filters, search.Filters)
+
+	search.Filters = domain.ItemFilters{}
+	require.NoError(t, db.UpdateSearch(context.Background(), search))
+
+	search, err = db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ItemFilters{}, search.Filters)
+}
+
+func Test_PriceDropAlertsArePersisted(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	savedSearch := domain.NewSavedSearch(&domain.SearchParams{SearchText: "barbour"})
+	savedSearch.PriceDropAlerts = domain.PriceDropAlerts{Enabled: true, MinDropPercent: 15}
+
+	searchID, err := db.CreateSearch(context.Background(), savedSearch)
+	require.NoError(t, err)
+
+	search, err := db.GetSearchByID(context.Background(), searchID)
+	require.NoError(t, err)
+	assert.Equal(t, savedSearch.PriceDropAlerts, search.PriceDropAlerts)
+}
@@ -1,26 +1,58 @@
 package storage
 
 import (
+	"context"
+	"time"
 	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/vinted"
 )
 
 type SearchStorage interface {
 	// Search CRUD operations
-	CreateSearch(search *domain.SavedSearch) (int, error)
-	GetSearchByID(id int) (*domain.SavedSearch, error)
-	GetAllSearches() ([]*domain.SavedSearch, error)
-	// UpdateSearch(search *domain.SavedSearch) error
-	// DeleteSearch(id int) error
+	CreateSearch(ctx context.Context, search *domain.SavedSearch) (int, error)
+	GetSearchByID(ctx context.Context, id int) (*domain.SavedSearch, error)
+	GetAllSearches(ctx context.Context) ([]*domain.SavedSearch, error)
+	UpdateSearch(ctx context.Context, search *domain.SavedSearch) error
+	DeleteSearch(ctx context.Context, id int) error
 
 	// Search status management
-	// UpdateLastChecked(searchID int) error
-	// SetSearchActive(searchID int, active bool) error
+	UpdateLastChecked(ctx context.Context, searchID int) error
+	SetSearchActive(ctx context.Context, searchID int, active bool) error
 
 	// Item tracking
-	MarkItemAsSeen(searchID int, vintedItemID int) error
-	IsItemSeen(searchID int, itemID int) (bool, error)
+	MarkItemAsSeen(ctx context.Context, searchID int, vintedItemID int) error
+	IsItemSeen(ctx context.Context, searchID int, itemID int) (bool, error)
 	// GetUnseenItems(searchID int, items []vinted.Item) ([]vinted.Item, error)
 
+	// Notification outbox
+	RecordNewItems(ctx context.Context, searchID int, targets []domain.NotificationTarget, items []vinted.Item, dealScores map[int64]float64) ([]vinted.Item, error)
+	GetDueOutboxEntries(ctx context.Context, now time.Time, limit int) ([]OutboxEntry, error)
+	GetDeadLetters(ctx context.Context) ([]OutboxEntry, error)
+	DeleteOutboxEntries(ctx context.Context, ids []int) error
+	RecordOutboxFailure(ctx context.Context, id int, lastError string, nextAttemptAt time.Time, dead bool) error
+	ReplayDeadLetter(ctx context.Context, id int) error
+	RecordPrices(ctx context.Context, searchID int, targets []domain.NotificationTarget, items []vinted.Item, minDropPercent float64) ([]PriceDrop, error)
+
+	// Item history
+	RecordObservations(ctx context.Context, searchID int, items []vinted.Item) error
+	GetItem(ctx context.Context, id int64) (*ItemRecord, error)
+	IsItemObserved(ctx context.Context, searchID int, itemID int64) (bool, error)
+	GetItemObservations(ctx context.Context, itemID int64) ([]ItemObservation, error)
+	GetSearchListings(ctx context.Context, searchID int) ([]SearchListing, error)
+	GetComparablePrices(ctx context.Context, item vinted.Item, since time.Time) ([]float64, error)
+
+	// Sold and removed item tracking
+	GetItemsToRecheck(ctx context.Context, checkedBefore time.Time, limit int) ([]ItemRecord, error)
+	GetItemSearchIDs(ctx context.Context, itemID int64) ([]int, error)
+	MarkItemAvailable(ctx context.Context, itemID int64) error
+	MarkItemRemoved(ctx context.Context, itemID int64) error
+	MarkItemSold(ctx context.Context, item vinted.Item, targets map[int][]domain.NotificationTarget) (time.Duration, error)
+
+	// Seller blocklist
+	BlockSeller(ctx context.Context, seller string, reason string) error
+	UnblockSeller(ctx context.Context, seller string) error
+	GetBlockedSellers(ctx context.Context) ([]BlockedSeller, error)
+
 	// Connection management
 	Close() error
 }
@@ -0,0 +1,155 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultAPIBaseURL = "https://api.telegram.org"
+
+type TelegramBot struct {
+	apiBaseURL string
+	token      string
+	client     *http.Client
+}
+
+// SendPhotoRequest is the payload for the Bot API sendPhoto method. Photo may be an image URL.
+type SendPhotoRequest struct {
+	ChatID      string                `json:"chat_id"`
+	Photo       string                `json:"photo"`
+	Caption     string                `json:"caption,omitempty"`
+	ParseMode   string                `json:"parse_mode,omitempty"`
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+// SendMessageRequest is the payload for the Bot API sendMessage method
+type SendMessageRequest struct {
+	ChatID      string                `json:"chat_id"`
+	Text        string                `json:"text"`
+	ParseMode   string                `json:"parse_mode,omitempty"`
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+type InlineKeyboardButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url,omitempty"`
+}
+
+// apiResponse is the envelope every Bot API method responds with
+type apiResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+// APIError is returned when the Bot API answers a request with ok set to false
+type APIError struct {
+	Method      string
+	StatusCode  int
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram %s failed (status: %d): %s", e.Method, e.StatusCode, e.Description)
+}
+
+// NewTelegramBot creates a new Telegram Bot API client with sensible defaults
+func NewTelegramBot(token string) *TelegramBot {
+	return &TelegramBot{
+		apiBaseURL: DefaultAPIBaseURL,
+		token:      token,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// NewTelegramBotWithClient creates a new Telegram Bot API client against a custom API server and HTTP client
+func NewTelegramBotWithClient(apiBaseURL, token string, client *http.Client) *TelegramBot {
+	return &TelegramBot{
+		apiBaseURL: strings.TrimSuffix(apiBaseURL, "/"),
+		token:      token,
+		client:     client,
+	}
+}
+
+func (t TelegramBot) SendPhoto(ctx context.Context, request SendPhotoRequest) error {
+	if request.Photo == "" {
+		return fmt.Errorf("photo cannot be empty")
+	}
+	return t.call(ctx, "sendPhoto", request)
+}
+
+func (t TelegramBot) SendMessage(ctx context.Context, request SendMessageRequest) error {
+	if request.Text == "" {
+		return fmt.Errorf("message text cannot be empty")
+	}
+	return t.call(ctx, "sendMessage", request)
+}
+
+func (t TelegramBot) call(ctx context.Context, method string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to marshal telegram request", "method", method, "error", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/%s", t.apiBaseURL, t.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		slog.Error("failed to create HTTP request", "error", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// The error embeds the request URL, which contains the bot token
+		err = t.redactError(err)
+		slog.Error("error posting to telegram", "method", method, "error", err)
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		slog.Error("failed to decode telegram response", "status_code", resp.StatusCode, "error", err)
+		return fmt.Errorf("failed to decode response (status: %d): %w", resp.StatusCode, err)
+	}
+
+	if !apiResp.OK {
+		slog.Error("failed to send message to Telegram",
+			"method", method,
+			"status_code", resp.StatusCode,
+			"description", apiResp.Description)
+		return &APIError{Method: method, StatusCode: resp.StatusCode, Description: apiResp.Description}
+	}
+
+	slog.Info("message sent successfully to Telegram", "method", method)
+	return nil
+}
+
+// redactError removes the bot token from the request URL quoted by an HTTP client error
+func (t TelegramBot) redactError(err error) error {
+	var urlErr *url.Error
+	if t.token == "" || !errors.As(err, &urlErr) {
+		return err
+	}
+
+	redacted := *urlErr
+	redacted.URL = strings.ReplaceAll(urlErr.URL, t.token, "REDACTED")
+	return &redacted
+}
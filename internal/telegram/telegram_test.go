@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBotAPIServer(t *testing.T, handler func(method string, body map[string]any) (int, string)) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		status, response := handler(r.URL.Path, body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_SendPhoto(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]any
+	server := newBotAPIServer(t, func(path string, body map[string]any) (int, string) {
+		gotPath, gotBody = path, body
+		return http.StatusOK, `{"ok":true,"result":{}}`
+	})
+
+	bot := NewTelegramBotWithClient(server.URL, "123:abc", server.Client())
+	err := bot.SendPhoto(context.Background(), SendPhotoRequest{
+		ChatID:    "-100200",
+		Photo:     "https://images.vinted.net/1.jpg",
+		Caption:   "<b>Barbour jacket</b>",
+		ParseMode: "HTML",
+		ReplyMarkup: &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{
+			{{Text: "Open on Vinted", URL: "https://www.vinted.co.uk/items/1"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/bot123:abc/sendPhoto", gotPath)
+	assert.Equal(t, "-100200", gotBody["chat_id"])
+	assert.Equal(t, "https://images.vinted.net/1.jpg", gotBody["photo"])
+	assert.Equal(t, "HTML", gotBody["parse_mode"])
+
+	keyboard := gotBody["reply_markup"].(map[string]any)["inline_keyboard"].([]any)
+	button := keyboard[0].([]any)[0].(map[string]any)
+	assert.Equal(t, "Open on Vinted", button["text"])
+	assert.Equal(t, "https://www.vinted.co.uk/items/1", button["url"])
+}
+
+func Test_SendMessage_ReturnsAPIError(t *testing.T) {
+	server := newBotAPIServer(t, func(path string, body map[string]any) (int, string) {
+		return http.StatusBadRequest, `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`
+	})
+
+	bot := NewTelegramBotWithClient(server.URL, "123:abc", server.Client())
+	err := bot.SendMessage(context.Background(), SendMessageRequest{ChatID: "42", Text: "hello"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chat not found")
+}
+
+func Test_SendMessage_WrapsTransportErrorWithoutToken(t *testing.T) {
+	server := newBotAPIServer(t, func(path string, body map[string]any) (int, string) {
+		return http.StatusOK, `{"ok":true,"result":{}}`
+	})
+	server.Close()
+
+	bot := NewTelegramBotWithClient(server.URL, "123:abc", server.Client())
+	err := bot.SendMessage(context.Background(), SendMessageRequest{ChatID: "42", Text: "hello"})
+	require.Error(t, err)
+
+	var urlErr *url.Error
+	assert.ErrorAs(t, err, &urlErr)
+	assert.Contains(t, err.Error(), "REDACTED")
+	assert.NotContains(t, err.Error(), "123:abc")
+}
@@ -1,70 +1,208 @@
 package vinted
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"vinted-watcher/internal/domain"
 )
 
 const PROXIES_ENV_VAR = "PROXY_URLS"
 const REFRESH_SESSION_ENDPOINT = "/session-refresh"
+const DEFAULT_MAX_REQUESTS_PER_PROXY = 2
+const ITEM_DETAILS_ENDPOINT = "/api/v2/items/%d"
+
+// ErrItemNotFound is returned when Vinted no longer has a listing for the item
+var ErrItemNotFound = errors.New("item not found")
 
 type VintedClient interface {
-	GetItems(params *domain.SearchParams) ([]Item, error)
+	GetItems(ctx context.Context, params *domain.SearchParams, opts PageOptions) ([]Item, error)
+	GetItemDetails(ctx context.Context, baseURL string, id int64) (*ItemDetails, error)
+}
+
+type ClientConfig struct {
+	// MaxRequestsPerProxy caps the number of in-flight requests through each proxy
+	// (or through the direct connection when no proxies are configured)
+	MaxRequestsPerProxy int
 }
 
+// Client is safe for concurrent use by multiple goroutines
 type Client struct {
-	baseURL      string
-	httpClient   *http.Client
+	// baseURL is the Vinted site whose session is initialised when the client is created
+	baseURL string
+	mu      sync.Mutex
+	// sessions holds a session per Vinted site, keyed by host, as each country's site issues
+	// its own cookies
+	sessions     map[string]*session
 	proxies      []url.URL
-	currentProxy int
+	routes       []*route
+	currentRoute int
+}
+
+type session struct {
+	// initMu is held while the session is initialised, so concurrent requests to a new site
+	// wait for a single initialisation rather than each starting their own
+	initMu sync.Mutex
+	// jar and initialised are guarded by Client.mu
+	jar http.CookieJar
+	// initialised is set once a session has been requested from the site
+	initialised bool
+}
+
+// route is a single outbound path to Vinted - either a proxy or the direct connection -
+// with its own concurrency limit
+type route struct {
+	proxy     *url.URL
+	transport http.RoundTripper
+	slots     chan struct{}
 }
 
 func NewClient(baseURL string) *Client {
-	jar, _ := cookiejar.New(nil)
-	client := Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Jar: jar,
-		},
-		proxies: getProxies(),
+	return NewClientWithConfig(baseURL, ClientConfig{
+		MaxRequestsPerProxy: DEFAULT_MAX_REQUESTS_PER_PROXY,
+	})
+}
+
+func NewClientWithConfig(baseURL string, config ClientConfig) *Client {
+	if config.MaxRequestsPerProxy <= 0 {
+		config.MaxRequestsPerProxy = DEFAULT_MAX_REQUESTS_PER_PROXY
 	}
 
+	client := &Client{
+		baseURL:  baseURL,
+		sessions: make(map[string]*session),
+		proxies:  getProxies(),
+	}
+	client.routes = newRoutes(client.proxies, config.MaxRequestsPerProxy)
+
 	if len(client.proxies) > 0 {
-		slog.Info("Using proxies", "proxies", client.proxies)
+		slog.Info("Using proxies", "proxies", client.proxies, "max_requests_per_proxy", config.MaxRequestsPerProxy)
 	} else {
 		slog.Info("No proxies configured")
 	}
 
-	err := client.InitSession()
+	err := client.InitSession(context.Background(), baseURL)
 	if err != nil {
 		slog.Error("Error initializing Vinted client session, continuing anyway", "error", err)
 	}
-	return &client
+	return client
 }
 
-// InitSession discards cookies and re-initiates a session.
-func (c *Client) InitSession() error {
+func newRoutes(proxies []url.URL, maxRequestsPerRoute int) []*route {
+	if len(proxies) == 0 {
+		return []*route{{
+			transport: http.DefaultTransport,
+			slots:     make(chan struct{}, maxRequestsPerRoute),
+		}}
+	}
+
+	routes := make([]*route, 0, len(proxies))
+	for i := range proxies {
+		proxy := &proxies[i]
+		routes = append(routes, &route{
+			proxy:     proxy,
+			transport: &http.Transport{Proxy: http.ProxyURL(proxy)},
+			slots:     make(chan struct{}, maxRequestsPerRoute),
+		})
+	}
+	return routes
+}
+
+// InitSession discards the cookies of the Vinted site at baseURL and re-initiates its session.
+func (c *Client) InitSession(ctx context.Context, baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("init session failed: %w", err)
+	}
+
+	s := c.session(u.Host)
+	s.initMu.Lock()
+	defer s.initMu.Unlock()
+
+	return c.initSession(ctx, s, baseURL)
+}
+
+// initSession requests a new session from the site at baseURL. The caller must hold s.initMu.
+func (c *Client) initSession(ctx context.Context, s *session, baseURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("init session failed: %w", err)
+	}
+
 	jar, _ := cookiejar.New(nil)
-	c.httpClient.Jar = jar
+	c.mu.Lock()
+	s.jar, s.initialised = jar, true
+	c.mu.Unlock()
 
-	req, _ := http.NewRequest(http.MethodGet, c.baseURL, nil)
 	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("init session failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	slog.Info("Session initialised", "status", resp.Status)
+	slog.Info("Session initialised", "host", req.URL.Host, "status", resp.Status)
 	return nil
 }
 
+// ensureSession initialises a session with the site the request is for, unless one has
+// already been requested
+func (c *Client) ensureSession(ctx context.Context, req *http.Request) {
+	s := c.session(req.URL.Host)
+
+	// Held across the check and the initialisation so only one request initialises the session
+	s.initMu.Lock()
+	defer s.initMu.Unlock()
+
+	c.mu.Lock()
+	initialised := s.initialised
+	c.mu.Unlock()
+
+	if initialised {
+		return
+	}
+
+	if err := c.initSession(ctx, s, siteURL(req.URL)); err != nil {
+		slog.Error("Error initializing Vinted session, continuing anyway", "host", req.URL.Host, "error", err)
+	}
+}
+
+// session returns the session for the host, creating an uninitialised one if there is none
+func (c *Client) session(host string) *session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.sessions[host]
+	if !ok {
+		jar, _ := cookiejar.New(nil)
+		s = &session{jar: jar}
+		c.sessions[host] = s
+	}
+	return s
+}
+
+// sessionJar returns the cookie jar for the host, creating an empty one if there is none
+func (c *Client) sessionJar(host string) http.CookieJar {
+	s := c.session(host)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return s.jar
+}
+
+// siteURL returns the root URL of the site a URL belongs to
+func siteURL(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
 //func (c *Client) RefreshSession() error {
 //	slog.Info("Refreshing Vinted session...")
 //	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s", c.baseURL, REFRESH_SESSION_ENDPOINT), nil)
@@ -78,70 +216,146 @@ func (c *Client) InitSession() error {
 //	return nil
 //}
 
-func (c *Client) GetItems(params *domain.SearchParams) ([]Item, error) {
+// GetItems fetches search results, newest first, walking pages until the options say to stop
+func (c *Client) GetItems(ctx context.Context, params *domain.SearchParams, opts PageOptions) ([]Item, error) {
+	return walkPages(ctx, params, opts, c.getItemsPage)
+}
+
+func (c *Client) getItemsPage(ctx context.Context, params *domain.SearchParams) (*ItemsResponse, error) {
 	apiURL, err := params.ToApiURL()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate API URL: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create API request: %w", err)
 	}
 	slog.Info("Making Vinted API request", "vinted_api_url", req.URL.String())
 
-	resp, err := c.Do(req)
+	resp, err := c.doAPIRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		slog.Warn("Got 401, re-initializing Vinted session")
-		resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed with status: %s", resp.Status)
+	}
 
-		if err := c.InitSession(); err != nil {
-			return nil, fmt.Errorf("failed to re-init session: %w", err)
-		}
+	var itemsResponse ItemsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&itemsResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode API response: %w", err)
+	}
 
-		resp, err = c.Do(req)
-		if err != nil {
-			return nil, err
-		}
+	return &itemsResponse, nil
+}
+
+// GetItemDetails fetches a single listing from the Vinted site at baseURL, returning
+// ErrItemNotFound if it has been deleted
+func (c *Client) GetItemDetails(ctx context.Context, baseURL string, id int64) (*ItemDetails, error) {
+	apiURL := baseURL + fmt.Sprintf(ITEM_DETAILS_ENDPOINT, id)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API request: %w", err)
+	}
+	slog.Info("Making Vinted API request", "vinted_api_url", req.URL.String())
+
+	resp, err := c.doAPIRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrItemNotFound
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API request failed with status: %s", resp.Status)
 	}
 
-	var itemsResponse ItemsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&itemsResponse); err != nil {
+	var detailsResponse ItemDetailsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&detailsResponse); err != nil {
 		return nil, fmt.Errorf("failed to decode API response: %w", err)
 	}
 
-	return itemsResponse.Items, nil
+	return &detailsResponse.Item, nil
+}
+
+// doAPIRequest sends an API request with the session for its site, re-initialising the session
+// and retrying once if it has expired
+func (c *Client) doAPIRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
+	c.ensureSession(ctx, req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		slog.Warn("Got 401, re-initializing Vinted session")
+		resp.Body.Close()
+
+		if err := c.InitSession(ctx, siteURL(req.URL)); err != nil {
+			return nil, fmt.Errorf("failed to re-init session: %w", err)
+		}
+
+		return c.Do(req)
+	}
+
+	return resp, nil
 }
 
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	setDefaultHeaders(req)
 
-	// No proxies configured
-	if len(c.proxies) == 0 {
-		return c.httpClient.Do(req)
+	// Rotate to next route
+	c.mu.Lock()
+	route := c.routes[c.currentRoute]
+	c.currentRoute = (c.currentRoute + 1) % len(c.routes)
+	c.mu.Unlock()
+
+	jar := c.sessionJar(req.URL.Host)
+
+	if route.proxy != nil {
+		slog.Info("Using proxy", "proxy", route.proxy.String())
 	}
 
-	// Rotate to next proxy
-	proxy := &c.proxies[c.currentProxy]
-	c.currentProxy = (c.currentProxy + 1) % len(c.proxies)
-	slog.Info("Using proxy", "proxy", proxy.String())
+	// Wait for a free slot on this route, released once the response body is closed
+	select {
+	case route.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
 
-	// Update the existing httpClient transport to use the proxy
-	transport := &http.Transport{
-		Proxy: http.ProxyURL(proxy),
+	httpClient := &http.Client{
+		Jar:       jar,
+		Transport: route.transport,
 	}
-	c.httpClient.Transport = transport
 
-	return c.httpClient.Do(req)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		<-route.slots
+		return nil, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { <-route.slots }}
+	return resp, nil
+}
+
+// releasingBody frees its route slot the first time it is closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
 }
 
 func getProxies() []url.URL {
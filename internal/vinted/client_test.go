@@ -1,9 +1,163 @@
 package vinted
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClient_GetItems(t *testing.T) {
 	// TODO: Implement test
 }
+
+func TestClient_Do_LimitsConcurrentRequestsPerRoute(t *testing.T) {
+	t.Setenv(PROXIES_ENV_VAR, "")
+
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClientWithConfig(server.URL, ClientConfig{MaxRequestsPerProxy: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	assert.Equal(t, int32(0), inFlight.Load())
+}
+
+func TestClient_GetItemDetails(t *testing.T) {
+	t.Setenv(PROXIES_ENV_VAR, "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/items/1":
+			w.Write([]byte(`{"code": 0, "item": {"id": 1, "title": "Bedale", "is_visible": true, "is_closed": true, "item_closing_action": "sold", "description": "Waxed jacket", "color1": "Olive", "measurement_length": 70, "photos": [{"url": "https://images.vinted.net/1.jpg"}], "user": {"id": 42, "feedback_reputation": 0.96, "feedback_count": 120}}}`))
+		case "/api/v2/items/2":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	details, err := client.GetItemDetails(context.Background(), server.URL, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), details.ID)
+	assert.Equal(t, "Bedale", details.Title)
+	assert.Equal(t, ItemSold, details.Availability())
+	assert.Equal(t, "Waxed jacket", details.Description)
+	assert.Equal(t, "Olive", details.Color1)
+	assert.Equal(t, 70.0, details.MeasurementLength)
+	require.Len(t, details.Photos, 1)
+	assert.Equal(t, 0.96, details.User.FeedbackReputation)
+	assert.Equal(t, 120, details.User.FeedbackCount)
+
+	_, err = client.GetItemDetails(context.Background(), server.URL, 2)
+	assert.ErrorIs(t, err, ErrItemNotFound)
+}
+
+func TestClient_KeepsSessionPerDomain(t *testing.T) {
+	t.Setenv(PROXIES_ENV_VAR, "")
+
+	newSite := func(sessionID string, sessionInits *atomic.Int32, receivedCookie *atomic.Value) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				sessionInits.Add(1)
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: sessionID, Path: "/"})
+				return
+			}
+
+			if cookie, err := r.Cookie("session"); err == nil {
+				receivedCookie.Store(cookie.Value)
+			}
+			w.Write([]byte(`{"item": {"id": 1}}`))
+		}))
+	}
+
+	var ukInits, frInits atomic.Int32
+	var ukCookie, frCookie atomic.Value
+	uk := newSite("uk", &ukInits, &ukCookie)
+	defer uk.Close()
+	fr := newSite("fr", &frInits, &frCookie)
+	defer fr.Close()
+
+	client := NewClient(uk.URL)
+	assert.Equal(t, int32(1), ukInits.Load())
+
+	// The other site's session is initialised on first use
+	_, err := client.GetItemDetails(context.Background(), fr.URL, 1)
+	require.NoError(t, err)
+	_, err = client.GetItemDetails(context.Background(), uk.URL, 1)
+	require.NoError(t, err)
+	_, err = client.GetItemDetails(context.Background(), fr.URL, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), ukInits.Load())
+	assert.Equal(t, int32(1), frInits.Load())
+	assert.Equal(t, "uk", ukCookie.Load())
+	assert.Equal(t, "fr", frCookie.Load())
+}
+
+func TestClient_InitialisesNewDomainSessionOnce(t *testing.T) {
+	t.Setenv(PROXIES_ENV_VAR, "")
+
+	var sessionInits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			sessionInits.Add(1)
+			// Slow enough for concurrent requests to overlap with the initialisation
+			time.Sleep(20 * time.Millisecond)
+			return
+		}
+		w.Write([]byte(`{"item": {"id": 1}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("http://127.0.0.1:0")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetItemDetails(context.Background(), server.URL, 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), sessionInits.Load(), "concurrent requests to a new site should share one session")
+}
@@ -1,5 +1,10 @@
 package vinted
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // ===== Top-level API Response =====
 type ItemsResponse struct {
 	Code                 int                  `json:"code"`
@@ -8,6 +13,12 @@ type ItemsResponse struct {
 	Items                []Item               `json:"items"`
 }
 
+// ItemDetailsResponse is returned by the item detail endpoint
+type ItemDetailsResponse struct {
+	Code int         `json:"code"`
+	Item ItemDetails `json:"item"`
+}
+
 // ===== Metadata =====
 type Pagination struct {
 	CurrentPage  int `json:"current_page"`
@@ -46,6 +57,61 @@ type Item struct {
 	Status               string                    `json:"status"`
 	ItemBox              ItemBox                   `json:"item_box,omitempty"`
 	SearchTrackingParams ItemsSearchTrackingParams `json:"search_tracking_params"`
+	// Details is set once the item has been enriched from the item detail endpoint
+	Details *ListingDetails `json:"details,omitempty"`
+}
+
+const (
+	ItemAvailable = "available"
+	ItemSold      = "sold"
+	ItemRemoved   = "removed"
+)
+
+// ListingDetails is what the item detail endpoint reports about a listing beyond its search result
+type ListingDetails struct {
+	Description string      `json:"description"`
+	Photos      []ItemPhoto `json:"photos"`
+	Color1      string      `json:"color1"`
+	Color2      string      `json:"color2"`
+	// MeasurementLength and MeasurementWidth are in centimetres, or zero if the seller didn't give them
+	MeasurementLength float64 `json:"measurement_length"`
+	MeasurementWidth  float64 `json:"measurement_width"`
+}
+
+// ItemDetails is a single listing as returned by the item detail endpoint, which unlike search
+// results also reports listings that have sold or been hidden
+type ItemDetails struct {
+	Item
+	ListingDetails
+	IsClosed          bool   `json:"is_closed"`
+	IsHidden          bool   `json:"is_hidden"`
+	IsReserved        bool   `json:"is_reserved"`
+	ItemClosingAction string `json:"item_closing_action"`
+}
+
+// Availability reports whether the listing is still available, has sold or has been removed
+// by its seller or Vinted
+func (d ItemDetails) Availability() string {
+	switch {
+	case d.ItemClosingAction == "sold" || (d.IsClosed && d.ItemClosingAction == ""):
+		return ItemSold
+	case d.IsClosed || d.IsHidden || !d.IsVisible:
+		return ItemRemoved
+	default:
+		return ItemAvailable
+	}
+}
+
+// WithDetails returns the search result enriched with the listing's details and its seller's rating
+func (i Item) WithDetails(details ItemDetails) Item {
+	listing := details.ListingDetails
+	i.Details = &listing
+	i.User.FeedbackReputation = details.User.FeedbackReputation
+	i.User.FeedbackCount = details.User.FeedbackCount
+	if i.Status == "" {
+		i.Status = details.Status
+	}
+	return i
 }
 
 type ItemsSearchTrackingParams struct {
@@ -69,6 +135,38 @@ type TotalItemPrice struct {
 	CurrencyCode string `json:"currency_code"`
 }
 
+// TotalPrice returns what the buyer pays for the item including fees. Vinted reports it as
+// TotalItemPrice; when that is missing it is derived from the price and service fee.
+func (i Item) TotalPrice() (float64, error) {
+	if i.TotalItemPrice.Amount != "" {
+		return ParseAmount(i.TotalItemPrice.Amount)
+	}
+
+	price, err := ParseAmount(i.Price.Amount)
+	if err != nil {
+		return 0, err
+	}
+
+	if i.ServiceFee.Amount == "" {
+		return price, nil
+	}
+
+	fee, err := ParseAmount(i.ServiceFee.Amount)
+	if err != nil {
+		return 0, err
+	}
+	return price + fee, nil
+}
+
+// ParseAmount parses a monetary amount as returned by the Vinted API, e.g. "12.50"
+func ParseAmount(amount string) (float64, error) {
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", amount, err)
+	}
+	return value, nil
+}
+
 // ===== Item Visuals =====
 type ItemPhoto struct {
 	ID                  int            `json:"id"`
@@ -123,6 +221,10 @@ type User struct {
 	ProfileURL string    `json:"profile_url"`
 	Photo      UserPhoto `json:"photo"`
 	Business   bool      `json:"business"`
+	// FeedbackReputation is the seller's rating between 0 and 1. It and FeedbackCount are only
+	// reported by the item detail endpoint.
+	FeedbackReputation float64 `json:"feedback_reputation"`
+	FeedbackCount      int     `json:"feedback_count"`
 }
 
 type UserPhoto struct {
@@ -0,0 +1,76 @@
+package vinted
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Item_TotalPrice(t *testing.T) {
+	item := Item{
+		Price:          Price{Amount: "40.00", CurrencyCode: "GBP"},
+		ServiceFee:     ServiceFee{Amount: "2.70", CurrencyCode: "GBP"},
+		TotalItemPrice: TotalItemPrice{Amount: "42.75", CurrencyCode: "GBP"},
+	}
+
+	total, err := item.TotalPrice()
+	require.NoError(t, err)
+	assert.Equal(t, 42.75, total, "the reported total should be preferred")
+
+	item.TotalItemPrice = TotalItemPrice{}
+	total, err = item.TotalPrice()
+	require.NoError(t, err)
+	assert.InDelta(t, 42.70, total, 0.001)
+
+	item.ServiceFee = ServiceFee{}
+	total, err = item.TotalPrice()
+	require.NoError(t, err)
+	assert.Equal(t, 40.0, total)
+
+	_, err = Item{}.TotalPrice()
+	assert.Error(t, err)
+}
+
+func Test_ItemDetails_Availability(t *testing.T) {
+	tests := []struct {
+		name     string
+		details  ItemDetails
+		expected string
+	}{
+		{"listed", ItemDetails{Item: Item{IsVisible: true}}, ItemAvailable},
+		{"reserved", ItemDetails{Item: Item{IsVisible: true}, IsReserved: true}, ItemAvailable},
+		{"sold", ItemDetails{IsClosed: true, ItemClosingAction: "sold"}, ItemSold},
+		{"closed without an action", ItemDetails{IsClosed: true}, ItemSold},
+		{"closed by the seller", ItemDetails{IsClosed: true, ItemClosingAction: "deleted"}, ItemRemoved},
+		{"hidden", ItemDetails{Item: Item{IsVisible: true}, IsHidden: true}, ItemRemoved},
+		{"not visible", ItemDetails{}, ItemRemoved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.details.Availability())
+		})
+	}
+}
+
+func Test_Item_WithDetails(t *testing.T) {
+	item := Item{ID: 1, Title: "Bedale", User: User{ID: 42, Login: "anna"}}
+	details := ItemDetails{
+		Item: Item{
+			Status: "Very good",
+			User:   User{ID: 42, FeedbackReputation: 0.96, FeedbackCount: 120},
+		},
+		ListingDetails: ListingDetails{Description: "Waxed jacket", Color1: "Olive"},
+	}
+
+	enriched := item.WithDetails(details)
+
+	require.NotNil(t, enriched.Details)
+	assert.Equal(t, "Waxed jacket", enriched.Details.Description)
+	assert.Equal(t, "Olive", enriched.Details.Color1)
+	assert.Equal(t, "Very good", enriched.Status)
+	assert.Equal(t, "anna", enriched.User.Login, "search result fields should be kept")
+	assert.Equal(t, 120, enriched.User.FeedbackCount)
+	assert.Nil(t, item.Details, "the original item should be unchanged")
+}
@@ -0,0 +1,64 @@
+package vinted
+
+import (
+	"context"
+	"log/slog"
+	"vinted-watcher/internal/domain"
+)
+
+// PageOptions controls how many pages of search results are fetched
+type PageOptions struct {
+	// MaxPages caps the number of pages fetched; zero or less fetches a single page
+	MaxPages int
+	// Stop is called with each result in order. Once it returns true no further pages are
+	// fetched, though the rest of the current page is still returned. Promoted items are
+	// skipped, as Vinted places them at the top regardless of age.
+	Stop func(item Item) bool
+}
+
+type pageFetcher func(ctx context.Context, params *domain.SearchParams) (*ItemsResponse, error)
+
+// walkPages fetches consecutive pages starting from params.Page until a page contains an item
+// that stops pagination, runs out of results, or MaxPages is reached. Items that move onto the
+// next page while paging are only returned once. Failing to fetch a later page returns the
+// items found so far.
+func walkPages(ctx context.Context, params *domain.SearchParams, opts PageOptions, fetch pageFetcher) ([]Item, error) {
+	maxPages := max(opts.MaxPages, 1)
+	firstPage := max(params.Page, 1)
+
+	items := make([]Item, 0)
+	seen := make(map[int64]bool)
+	for page := firstPage; page < firstPage+maxPages; page++ {
+		pageParams := *params
+		pageParams.Page = page
+
+		response, err := fetch(ctx, &pageParams)
+		if err != nil {
+			if page == firstPage {
+				return nil, err
+			}
+			slog.Warn("Error fetching further results, using pages fetched so far", "page", page, "err", err.Error())
+			return items, nil
+		}
+
+		stop := false
+		for _, item := range response.Items {
+			if seen[item.ID] {
+				continue
+			}
+			seen[item.ID] = true
+			items = append(items, item)
+
+			if opts.Stop != nil && !item.Promoted && opts.Stop(item) {
+				stop = true
+			}
+		}
+
+		lastPage := response.Pagination.TotalPages > 0 && page >= response.Pagination.TotalPages
+		if stop || lastPage || len(response.Items) == 0 {
+			break
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,111 @@
+package vinted
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"vinted-watcher/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePages serves numbered pages of results, recording which were requested
+type fakePages struct {
+	pages     map[int][]Item
+	failPage  int
+	requested []int
+}
+
+func (f *fakePages) fetch(ctx context.Context, params *domain.SearchParams) (*ItemsResponse, error) {
+	f.requested = append(f.requested, params.Page)
+	if params.Page == f.failPage {
+		return nil, errors.New("request failed")
+	}
+	return &ItemsResponse{
+		Items:      f.pages[params.Page],
+		Pagination: Pagination{CurrentPage: params.Page, TotalPages: len(f.pages)},
+	}, nil
+}
+
+func itemIDs(items []Item) []int64 {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func Test_WalkPages_StopsAtPageWithStopItem(t *testing.T) {
+	pages := &fakePages{pages: map[int][]Item{
+		1: {{ID: 9}, {ID: 8}},
+		2: {{ID: 7}, {ID: 6}},
+		3: {{ID: 5}, {ID: 4}},
+	}}
+
+	items, err := walkPages(context.Background(), &domain.SearchParams{SearchText: "barbour"}, PageOptions{
+		MaxPages: 5,
+		Stop:     func(item Item) bool { return item.ID == 7 },
+	}, pages.fetch)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{9, 8, 7, 6}, itemIDs(items))
+	assert.Equal(t, []int{1, 2}, pages.requested)
+}
+
+func Test_WalkPages_RespectsLimits(t *testing.T) {
+	pages := &fakePages{pages: map[int][]Item{
+		1: {{ID: 9}, {ID: 8}},
+		2: {{ID: 8}, {ID: 7}},
+		3: {{ID: 6}},
+	}}
+
+	// Items pushed onto the next page by new listings are only returned once
+	items, err := walkPages(context.Background(), &domain.SearchParams{SearchText: "barbour"}, PageOptions{MaxPages: 2}, pages.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{9, 8, 7}, itemIDs(items))
+	assert.Equal(t, []int{1, 2}, pages.requested)
+
+	// Paging ends at the last page
+	pages.requested = nil
+	items, err = walkPages(context.Background(), &domain.SearchParams{SearchText: "barbour"}, PageOptions{MaxPages: 10}, pages.fetch)
+	require.NoError(t, err)
+	assert.Len(t, items, 4)
+	assert.Equal(t, []int{1, 2, 3}, pages.requested)
+
+	// A single page is fetched by default
+	pages.requested = nil
+	_, err = walkPages(context.Background(), &domain.SearchParams{SearchText: "barbour"}, PageOptions{}, pages.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, pages.requested)
+}
+
+func Test_WalkPages_IgnoresPromotedItems(t *testing.T) {
+	pages := &fakePages{pages: map[int][]Item{
+		1: {{ID: 1, Promoted: true}, {ID: 9}},
+		2: {{ID: 8}},
+	}}
+
+	items, err := walkPages(context.Background(), &domain.SearchParams{SearchText: "barbour"}, PageOptions{
+		MaxPages: 5,
+		Stop:     func(item Item) bool { return item.ID < 5 },
+	}, pages.fetch)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 9, 8}, itemIDs(items))
+}
+
+func Test_WalkPages_HandlesErrors(t *testing.T) {
+	pages := &fakePages{pages: map[int][]Item{
+		1: {{ID: 9}},
+		2: {{ID: 8}},
+	}, failPage: 2}
+
+	items, err := walkPages(context.Background(), &domain.SearchParams{SearchText: "barbour"}, PageOptions{MaxPages: 5}, pages.fetch)
+	require.NoError(t, err, "results already fetched should be kept")
+	assert.Equal(t, []int64{9}, itemIDs(items))
+
+	pages.failPage = 1
+	_, err = walkPages(context.Background(), &domain.SearchParams{SearchText: "barbour"}, PageOptions{MaxPages: 5}, pages.fetch)
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package vinted
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"vinted-watcher/internal/domain"
 )
@@ -13,13 +14,22 @@ func ParseVintedURL(u string) (*domain.SearchParams, error) {
 		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
 
-	params := &domain.SearchParams{}
+	vintedDomain, err := domain.NormaliseVintedDomain(parsedURL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &domain.SearchParams{Domain: vintedDomain}
 
 	if searchText := parsedURL.Query().Get("search_text"); searchText != "" {
 		params.SearchText = searchText
 	}
 
+	// Each site only lists prices in its own currency
 	if currency := parsedURL.Query().Get("currency"); currency != "" {
+		if expected := domain.VintedDomainCurrency(vintedDomain); currency != expected {
+			return nil, fmt.Errorf("currency %s is not supported on %s, expected %s", currency, vintedDomain, expected)
+		}
 		params.Currency = currency
 	}
 
@@ -63,6 +73,51 @@ func ParseVintedURL(u string) (*domain.SearchParams, error) {
 		params.PatternsIDs = patternsIDs
 	}
 
+	if colorIDsString := parsedURL.Query()["color_ids[]"]; len(colorIDsString) > 0 {
+		colorIDs, err := convertCommaSeparatedToIntSlice(colorIDsString)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse colorIDs: %w", err)
+		}
+		params.ColorIDs = colorIDs
+	}
+
+	if materialIDsString := parsedURL.Query()["material_ids[]"]; len(materialIDsString) > 0 {
+		materialIDs, err := convertCommaSeparatedToIntSlice(materialIDsString)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse materialIDs: %w", err)
+		}
+		params.MaterialIDs = materialIDs
+	}
+
+	if videoGamePlatformIDsString := parsedURL.Query()["video_game_platform_ids[]"]; len(videoGamePlatformIDsString) > 0 {
+		videoGamePlatformIDs, err := convertCommaSeparatedToIntSlice(videoGamePlatformIDsString)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse videoGamePlatformIDs: %w", err)
+		}
+		params.VideoGamePlatformIDs = videoGamePlatformIDs
+	}
+
+	if videoGameRatingIDsString := parsedURL.Query()["video_game_rating_ids[]"]; len(videoGameRatingIDsString) > 0 {
+		videoGameRatingIDs, err := convertCommaSeparatedToIntSlice(videoGameRatingIDsString)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse videoGameRatingIDs: %w", err)
+		}
+		params.VideoGameRatingIDs = videoGameRatingIDs
+	}
+
+	// Disposal is sent as disposal[] from the filter bar but as a single disposal elsewhere
+	if disposalString := append(parsedURL.Query()["disposal[]"], parsedURL.Query()["disposal"]...); len(disposalString) > 0 {
+		disposalIDs, err := convertCommaSeparatedToIntSlice(disposalString)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse disposal: %w", err)
+		}
+		params.DisposalIDs = disposalIDs
+	}
+
+	if order := parsedURL.Query().Get("order"); order != domain.DefaultOrder {
+		params.Order = order
+	}
+
 	if pageStr := parsedURL.Query().Get("page"); pageStr != "" {
 		page, err := strconv.Atoi(pageStr)
 		if err != nil {
@@ -98,6 +153,50 @@ func ParseVintedURL(u string) (*domain.SearchParams, error) {
 	return params, nil
 }
 
+// knownParams are the query parameters ParseVintedURL understands, plus ones the Vinted website
+// adds that have no effect on the results
+var knownParams = map[string]bool{
+	"search_text":               true,
+	"currency":                  true,
+	"catalog[]":                 true,
+	"size_ids[]":                true,
+	"brand_ids[]":               true,
+	"status_ids[]":              true,
+	"patterns_ids[]":            true,
+	"color_ids[]":               true,
+	"material_ids[]":            true,
+	"video_game_platform_ids[]": true,
+	"video_game_rating_ids[]":   true,
+	"disposal[]":                true,
+	"disposal":                  true,
+	"order":                     true,
+	"page":                      true,
+	"price_from":                true,
+	"price_to":                  true,
+	"time":                      true,
+	"search_id":                 true,
+	"search_by_image_uuid":      true,
+	"catalog_from":              true,
+}
+
+// UnknownParams returns the sorted query parameters of a Vinted URL that ParseVintedURL ignores,
+// so callers can warn that the saved search may match more than the URL does.
+func UnknownParams(u string) []string {
+	parsedURL, err := url.Parse(u)
+	if err != nil {
+		return nil
+	}
+
+	var unknown []string
+	for key := range parsedURL.Query() {
+		if !knownParams[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
 func convertCommaSeparatedToIntSlice(input []string) ([]int, error) {
 	var result []int
 	for _, s := range input {
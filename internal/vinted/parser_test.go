@@ -1,7 +1,10 @@
 package vinted
 
 import (
+	"math/rand"
+	"reflect"
 	"testing"
+	"testing/quick"
 	"vinted-watcher/internal/domain"
 
 	"github.com/stretchr/testify/require"
@@ -12,6 +15,7 @@ func Test_Parse_WithFullVintedURL(t *testing.T) {
 
 	expected := &domain.SearchParams{
 		SearchText:  "universal works men",
+		Domain:      "www.vinted.co.uk",
 		CatalogIDs:  []int{2051, 2052},
 		Page:        1,
 		SizeIDs:     []int{209, 210},
@@ -34,6 +38,7 @@ func Test_Parse_MinimalURL(t *testing.T) {
 
 	expected := &domain.SearchParams{
 		SearchText: "universal works",
+		Domain:     "www.vinted.co.uk",
 		BrandIDs:   []int{123},
 	}
 
@@ -49,3 +54,154 @@ func Test_Parse_FailsIfBrandIDsIsMissing(t *testing.T) {
 	_, err := ParseVintedURL(url)
 	require.Error(t, err, "should return an error for invalid brand_ids")
 }
+
+func Test_Parse_PreservesAllFilters(t *testing.T) {
+	url := "https://www.vinted.co.uk/catalog?search_text=zelda&brand_ids[]=123&color_ids[]=1&color_ids[]=12&material_ids[]=44&video_game_platform_ids[]=1281&video_game_rating_ids[]=3&disposal[]=1&order=price_low_to_high"
+
+	expected := &domain.SearchParams{
+		SearchText:           "zelda",
+		Domain:               "www.vinted.co.uk",
+		BrandIDs:             []int{123},
+		ColorIDs:             []int{1, 12},
+		MaterialIDs:          []int{44},
+		VideoGamePlatformIDs: []int{1281},
+		VideoGameRatingIDs:   []int{3},
+		DisposalIDs:          []int{1},
+		Order:                "price_low_to_high",
+	}
+
+	actual, err := ParseVintedURL(url)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func Test_Parse_AcceptsSingleDisposal(t *testing.T) {
+	actual, err := ParseVintedURL("https://www.vinted.co.uk/catalog?search_text=zelda&brand_ids[]=123&disposal=2")
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, actual.DisposalIDs)
+}
+
+func Test_UnknownParams(t *testing.T) {
+	url := "https://www.vinted.co.uk/catalog?search_text=zelda&time=1754855320&brand_ids[]=123&order=newest_first&is_for_swap=1&unisex_catalog_feature=1&search_id=42"
+
+	require.Equal(t, []string{"is_for_swap", "unisex_catalog_feature"}, UnknownParams(url))
+	require.Empty(t, UnknownParams("https://www.vinted.co.uk/catalog?search_text=zelda&brand_ids[]=123"))
+}
+
+func Test_Parse_DetectsDomain(t *testing.T) {
+	actual, err := ParseVintedURL("https://vinted.fr/catalog?search_text=barbour&brand_ids[]=123&currency=EUR")
+	require.NoError(t, err)
+	require.Equal(t, "www.vinted.fr", actual.Domain)
+	require.Equal(t, "EUR", actual.CurrencyCode())
+
+	actual, err = ParseVintedURL("https://www.vinted.de/catalog?search_text=barbour&brand_ids[]=123")
+	require.NoError(t, err)
+	require.Equal(t, "www.vinted.de", actual.Domain)
+	require.Equal(t, "EUR", actual.CurrencyCode(), "the site's currency should be used by default")
+}
+
+func Test_Parse_RejectsUnsupportedDomainsAndCurrencies(t *testing.T) {
+	_, err := ParseVintedURL("https://www.example.com/catalog?search_text=barbour&brand_ids[]=123")
+	require.Error(t, err)
+
+	_, err = ParseVintedURL("https://www.vinted.co.uk/catalog?search_text=barbour&brand_ids[]=123&currency=EUR")
+	require.Error(t, err)
+}
+
+// roundTripParams generates search parameters that ParseVintedURL accepts, covering every
+// supported filter
+type roundTripParams struct {
+	params *domain.SearchParams
+}
+
+var roundTripDomains = []string{"www.vinted.co.uk", "www.vinted.fr", "www.vinted.de", "www.vinted.nl", "www.vinted.pl"}
+
+// roundTripOrders are the non-default sort orders, as newest_first is stored as empty
+var roundTripOrders = []string{"", "relevance", "price_low_to_high", "price_high_to_low"}
+
+// roundTripRunes includes characters with special meaning in URLs
+var roundTripRunes = []rune("abcXYZ019 &=+%#?/[]'\"éß日本")
+
+func (roundTripParams) Generate(r *rand.Rand, size int) reflect.Value {
+	vintedDomain := roundTripDomains[r.Intn(len(roundTripDomains))]
+
+	searchText := make([]rune, 1+r.Intn(size+1))
+	for i := range searchText {
+		searchText[i] = roundTripRunes[r.Intn(len(roundTripRunes))]
+	}
+
+	ids := func(required bool) []int {
+		count := r.Intn(4)
+		if required {
+			count++
+		}
+		if count == 0 {
+			return nil
+		}
+		result := make([]int, count)
+		for i := range result {
+			result[i] = r.Intn(1_000_000)
+		}
+		return result
+	}
+
+	price := func() float64 {
+		if r.Intn(3) == 0 {
+			return 0
+		}
+		return r.Float64() * 1000
+	}
+
+	params := &domain.SearchParams{
+		SearchText:  string(searchText),
+		Domain:      vintedDomain,
+		CatalogIDs:  ids(false),
+		SizeIDs:     ids(false),
+		BrandIDs:    ids(true),
+		StatusIDs:   ids(false),
+		PatternsIDs: ids(false),
+		PriceFrom:   price(),
+		PriceTo:     price(),
+
+		ColorIDs:             ids(false),
+		MaterialIDs:          ids(false),
+		VideoGamePlatformIDs: ids(false),
+		VideoGameRatingIDs:   ids(false),
+		DisposalIDs:          ids(false),
+		Order:                roundTripOrders[r.Intn(len(roundTripOrders))],
+	}
+
+	if r.Intn(2) == 0 {
+		params.Page = 1 + r.Intn(100)
+	}
+
+	if r.Intn(2) == 0 {
+		params.Currency = domain.VintedDomainCurrency(vintedDomain)
+	}
+
+	return reflect.ValueOf(roundTripParams{params: params})
+}
+
+func Test_Parse_RoundTripsWebURL(t *testing.T) {
+	roundTrips := func(p roundTripParams) bool {
+		webURL, err := p.params.ToWebURL()
+		if err != nil {
+			t.Logf("ToWebURL(%+v) failed: %v", p.params, err)
+			return false
+		}
+
+		parsed, err := ParseVintedURL(webURL)
+		if err != nil {
+			t.Logf("ParseVintedURL(%q) failed: %v", webURL, err)
+			return false
+		}
+
+		if !reflect.DeepEqual(p.params, parsed) {
+			t.Logf("%q parsed as %+v, expected %+v", webURL, parsed, p.params)
+			return false
+		}
+		return true
+	}
+
+	require.NoError(t, quick.Check(roundTrips, &quick.Config{MaxCount: 1000}))
+}
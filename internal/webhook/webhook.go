@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Vinted-Watcher-Signature"
+	TimestampHeader = "X-Vinted-Watcher-Timestamp"
+	EventHeader     = "X-Vinted-Watcher-Event"
+
+	signaturePrefix = "sha256="
+)
+
+// SignedWebhook POSTs JSON payloads to an endpoint, signing each with HMAC-SHA256 so receivers
+// can verify the request came from us
+type SignedWebhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSignedWebhook creates a new signed webhook client with sensible defaults
+func NewSignedWebhook(url, secret string) *SignedWebhook {
+	return NewSignedWebhookWithClient(url, secret, &http.Client{
+		Timeout: 10 * time.Second,
+	})
+}
+
+// NewSignedWebhookWithClient creates a new signed webhook client with a custom HTTP client
+func NewSignedWebhookWithClient(url, secret string, client *http.Client) *SignedWebhook {
+	return &SignedWebhook{
+		url:    url,
+		secret: []byte(secret),
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Post sends a JSON body for the given event type
+func (w *SignedWebhook) Post(ctx context.Context, event string, body []byte) error {
+	timestamp := strconv.FormatInt(w.now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		slog.Error("failed to create HTTP request", "error", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(w.secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		slog.Error("error posting to webhook", "error", err)
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		slog.Info("webhook delivered successfully", "status_code", resp.StatusCode, "event", event)
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	slog.Error("webhook delivery failed",
+		"status_code", resp.StatusCode,
+		"response_body", string(respBody))
+
+	return fmt.Errorf("webhook delivery failed (status: %d): %s", resp.StatusCode, string(respBody))
+}
+
+// Sign returns the signature header value for a payload: the hex HMAC-SHA256 of "<timestamp>.<body>".
+// Including the timestamp lets receivers reject replayed requests.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for the timestamp and body
+func Verify(secret []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Post_SignsPayload(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"version":1}`)
+
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "item.new", r.Header.Get(EventHeader))
+		assert.Equal(t, "1756713600", r.Header.Get(TimestampHeader))
+		verified = Verify(secret, r.Header.Get(TimestampHeader), received, r.Header.Get(SignatureHeader))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	w := NewSignedWebhookWithClient(server.URL, string(secret), server.Client())
+	w.now = func() time.Time { return time.Unix(1756713600, 0) }
+
+	require.NoError(t, w.Post(context.Background(), "item.new", body))
+	assert.True(t, verified)
+}
+
+func Test_Sign_KnownVector(t *testing.T) {
+	// echo -n '1756713600.{"version":1}' | openssl dgst -sha256 -hmac s3cret
+	signature := Sign([]byte("s3cret"), "1756713600", []byte(`{"version":1}`))
+	assert.Equal(t, "sha256=73c681ad1b2d9dd8c4630d5ba7ad685d8a422b55f2fed44f82480ed2be7688a8", signature)
+
+	assert.True(t, Verify([]byte("s3cret"), "1756713600", []byte(`{"version":1}`), signature))
+	assert.False(t, Verify([]byte("wrong"), "1756713600", []byte(`{"version":1}`), signature))
+	assert.False(t, Verify([]byte("s3cret"), "1756713601", []byte(`{"version":1}`), signature))
+}
+
+func Test_Post_ReturnsErrorOnFailureStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer server.Close()
+
+	err := NewSignedWebhook(server.URL, "s3cret").Post(context.Background(), "item.new", []byte(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
@@ -5,9 +5,14 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+	"vinted-watcher/internal/domain"
+	"vinted-watcher/internal/email"
 	_ "vinted-watcher/internal/logger"
+	"vinted-watcher/internal/notifier"
+	"vinted-watcher/internal/scheduler"
 	"vinted-watcher/internal/scraper"
 	"vinted-watcher/internal/server"
 	"vinted-watcher/internal/storage"
@@ -15,9 +20,37 @@ import (
 )
 
 const DISCORD_WEBHOOK_URL_ENV_VAR = "DISCORD_WEBHOOK_URL"
+const SLACK_WEBHOOK_URL_ENV_VAR = "SLACK_WEBHOOK_URL"
+const TELEGRAM_BOT_TOKEN_ENV_VAR = "TELEGRAM_BOT_TOKEN"
+const TELEGRAM_CHAT_ID_ENV_VAR = "TELEGRAM_CHAT_ID"
+const SMTP_HOST_ENV_VAR = "SMTP_HOST"
+const SMTP_PORT_ENV_VAR = "SMTP_PORT"
+const SMTP_USERNAME_ENV_VAR = "SMTP_USERNAME"
+const SMTP_PASSWORD_ENV_VAR = "SMTP_PASSWORD"
+const SMTP_FROM_ENV_VAR = "SMTP_FROM"
+const EMAIL_DIGEST_TO_ENV_VAR = "EMAIL_DIGEST_TO"
+const EMAIL_DIGEST_SCHEDULE_ENV_VAR = "EMAIL_DIGEST_SCHEDULE"
+const EMAIL_DIGEST_CHECK_INTERVAL = 1 * time.Minute
+const NOTIFICATION_OUTBOX_INTERVAL = 30 * time.Second
+const WEBHOOK_URL_ENV_VAR = "WEBHOOK_URL"
+const WEBHOOK_SECRET_ENV_VAR = "WEBHOOK_SECRET"
+const NTFY_TOPIC_URL_ENV_VAR = "NTFY_TOPIC_URL"
+const NTFY_TOKEN_ENV_VAR = "NTFY_TOKEN"
+const NTFY_PRIORITY_ENV_VAR = "NTFY_PRIORITY"
+const GOTIFY_URL_ENV_VAR = "GOTIFY_URL"
+const GOTIFY_APP_TOKEN_ENV_VAR = "GOTIFY_APP_TOKEN"
+const GOTIFY_PRIORITY_ENV_VAR = "GOTIFY_PRIORITY"
 const DB_PATH_ENV_VAR = "DB_PATH"
 const DEFAULT_DB_PATH = "./vinted.db"
 const VINTED_BASE_URL = "http://www.vinted.co.uk"
+const SCRAPER_CONCURRENCY_ENV_VAR = "SCRAPER_CONCURRENCY"
+const MAX_REQUESTS_PER_PROXY_ENV_VAR = "MAX_REQUESTS_PER_PROXY"
+const EXCLUDE_BUSINESS_SELLERS_ENV_VAR = "EXCLUDE_BUSINESS_SELLERS"
+const TRACK_SOLD_ITEMS_ENV_VAR = "TRACK_SOLD_ITEMS"
+const NOTIFY_SOLD_ITEMS_ENV_VAR = "NOTIFY_SOLD_ITEMS"
+const ITEM_AVAILABILITY_CHECK_INTERVAL = 15 * time.Minute
+const ENRICH_NEW_ITEMS_ENV_VAR = "ENRICH_NEW_ITEMS"
+const MAX_PAGES_PER_SEARCH_ENV_VAR = "MAX_PAGES_PER_SEARCH"
 
 // Test code - will eventually become server entrypoint
 func main() {
@@ -39,14 +72,54 @@ func main() {
 		return
 	}
 
-	vintedClient := vinted.NewClient(VINTED_BASE_URL)
+	vintedClient := vinted.NewClientWithConfig(VINTED_BASE_URL, vinted.ClientConfig{
+		MaxRequestsPerProxy: getEnvInt(MAX_REQUESTS_PER_PROXY_ENV_VAR, vinted.DEFAULT_MAX_REQUESTS_PER_PROXY),
+	})
+
+	// Email digests can only be sent when SMTP is configured
+	var mailer notifier.Mailer
+	if smtpHost := os.Getenv(SMTP_HOST_ENV_VAR); smtpHost != "" {
+		mailer = email.NewSMTPMailer(email.SMTPConfig{
+			Host:     smtpHost,
+			Port:     os.Getenv(SMTP_PORT_ENV_VAR),
+			Username: os.Getenv(SMTP_USERNAME_ENV_VAR),
+			Password: os.Getenv(SMTP_PASSWORD_ENV_VAR),
+			From:     os.Getenv(SMTP_FROM_ENV_VAR),
+		})
+	}
+	notifierDeps := notifier.Dependencies{DigestStore: db, Mailer: mailer}
+
+	defaultNotificationTargets := getDefaultNotificationTargets()
+	if err := notifier.Validate(defaultNotificationTargets, notifierDeps); err != nil {
+		slog.Error("Invalid notification configuration", "error", err)
+		return
+	}
 
 	vintedScraper := scraper.NewScraper(vintedClient, db, scraper.ScraperConfig{
-		LookbackPeriod:                24 * time.Hour,
-		DiscordNotificationWebhookURL: os.Getenv(DISCORD_WEBHOOK_URL_ENV_VAR),
+		LookbackPeriod:             24 * time.Hour,
+		DefaultNotificationTargets: defaultNotificationTargets,
+		Notifiers:                  notifierDeps,
+		Concurrency:                getEnvInt(SCRAPER_CONCURRENCY_ENV_VAR, scraper.DefaultConcurrency),
+		MaxPages:                   getEnvInt(MAX_PAGES_PER_SEARCH_ENV_VAR, scraper.DefaultMaxPages),
+		ExcludeBusinessSellers:     getEnvBool(EXCLUDE_BUSINESS_SELLERS_ENV_VAR, false),
+		EnrichNewItems:             getEnvBool(ENRICH_NEW_ITEMS_ENV_VAR, false),
+		Availability: scraper.AvailabilityConfig{
+			NotifySold: getEnvBool(NOTIFY_SOLD_ITEMS_ENV_VAR, false),
+		},
 	})
 
-	go startScheduler(ctx, vintedScraper, 1*time.Hour)
+	go vintedScraper.Outbox().Run(ctx, NOTIFICATION_OUTBOX_INTERVAL)
+
+	if getEnvBool(TRACK_SOLD_ITEMS_ENV_VAR, false) {
+		go vintedScraper.RunAvailabilityChecks(ctx, ITEM_AVAILABILITY_CHECK_INTERVAL)
+	}
+
+	if mailer != nil {
+		go notifier.NewDigestSender(db, mailer).Run(ctx, EMAIL_DIGEST_CHECK_INTERVAL)
+	}
+
+	searchScheduler := scheduler.NewScheduler(vintedScraper, db, scheduler.DefaultTickInterval)
+	go searchScheduler.Run(ctx)
 
 	httpServer := server.NewServer(db, vintedScraper)
 	if err := httpServer.Start(ctx); err != nil {
@@ -54,40 +127,104 @@ func main() {
 	}
 }
 
-func startScheduler(ctx context.Context, scraper *scraper.Scraper, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	safeScrape(scraper) // Initial scrape on startup
-	for {
-		select {
-		case <-ticker.C:
-			safeScrape(scraper)
-		case <-ctx.Done():
-			slog.Info("Stopping scheduled scrape...")
-			return
-		}
+func getEnvVar(varName string, defaultValue string) string {
+	value := os.Getenv(varName)
+	if value == "" {
+		return defaultValue
 	}
+	return value
 }
 
-func safeScrape(scraper *scraper.Scraper) {
-	defer func() {
-		if r := recover(); r != nil {
-			slog.Error("Panic occurred during scraping:", "error", r)
-		}
-	}()
-	scraperResult, err := scraper.Scrape()
+func getEnvInt(varName string, defaultValue int) int {
+	value := os.Getenv(varName)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
 	if err != nil {
-		slog.Error("Error scraping:", "error", err)
-		return
+		slog.Warn("Invalid integer environment variable, using default", "name", varName, "value", value, "default", defaultValue)
+		return defaultValue
 	}
-	slog.Debug("Scrape stats", "new_item_count", len(scraperResult.NewItems), "processed_searches_count", scraperResult.ProcessedSearches)
+	return parsed
 }
 
-func getEnvVar(varName string, defaultValue string) string {
+func getEnvBool(varName string, defaultValue bool) bool {
 	value := os.Getenv(varName)
 	if value == "" {
 		return defaultValue
 	}
-	return value
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("Invalid boolean environment variable, using default", "name", varName, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getDefaultNotificationTargets builds the notification targets used by searches that don't specify their own
+func getDefaultNotificationTargets() []domain.NotificationTarget {
+	targets := make([]domain.NotificationTarget, 0)
+
+	if webhookURL := os.Getenv(DISCORD_WEBHOOK_URL_ENV_VAR); webhookURL != "" {
+		targets = append(targets, domain.NotificationTarget{Type: domain.NotificationTargetDiscord, URL: webhookURL})
+	}
+
+	if webhookURL := os.Getenv(SLACK_WEBHOOK_URL_ENV_VAR); webhookURL != "" {
+		targets = append(targets, domain.NotificationTarget{Type: domain.NotificationTargetSlack, URL: webhookURL})
+	}
+
+	botToken, chatID := os.Getenv(TELEGRAM_BOT_TOKEN_ENV_VAR), os.Getenv(TELEGRAM_CHAT_ID_ENV_VAR)
+	if botToken != "" && chatID != "" {
+		targets = append(targets, domain.NotificationTarget{
+			Type: domain.NotificationTargetTelegram,
+			Options: map[string]string{
+				notifier.TelegramBotTokenOption: botToken,
+				notifier.TelegramChatIDOption:   chatID,
+			},
+		})
+	}
+
+	if recipients := os.Getenv(EMAIL_DIGEST_TO_ENV_VAR); recipients != "" {
+		targets = append(targets, domain.NotificationTarget{
+			Type: domain.NotificationTargetEmail,
+			Options: map[string]string{
+				notifier.EmailToOption:       recipients,
+				notifier.EmailScheduleOption: os.Getenv(EMAIL_DIGEST_SCHEDULE_ENV_VAR),
+			},
+		})
+	}
+
+	if webhookURL := os.Getenv(WEBHOOK_URL_ENV_VAR); webhookURL != "" {
+		targets = append(targets, domain.NotificationTarget{
+			Type:    domain.NotificationTargetWebhook,
+			URL:     webhookURL,
+			Options: map[string]string{notifier.WebhookSecretOption: os.Getenv(WEBHOOK_SECRET_ENV_VAR)},
+		})
+	}
+
+	if topicURL := os.Getenv(NTFY_TOPIC_URL_ENV_VAR); topicURL != "" {
+		targets = append(targets, domain.NotificationTarget{
+			Type: domain.NotificationTargetNtfy,
+			URL:  topicURL,
+			Options: map[string]string{
+				notifier.NtfyTokenOption:    os.Getenv(NTFY_TOKEN_ENV_VAR),
+				notifier.NtfyPriorityOption: os.Getenv(NTFY_PRIORITY_ENV_VAR),
+			},
+		})
+	}
+
+	if serverURL := os.Getenv(GOTIFY_URL_ENV_VAR); serverURL != "" {
+		targets = append(targets, domain.NotificationTarget{
+			Type: domain.NotificationTargetGotify,
+			URL:  serverURL,
+			Options: map[string]string{
+				notifier.GotifyTokenOption:    os.Getenv(GOTIFY_APP_TOKEN_ENV_VAR),
+				notifier.GotifyPriorityOption: os.Getenv(GOTIFY_PRIORITY_ENV_VAR),
+			},
+		})
+	}
+
+	return targets
 }